@@ -39,6 +39,12 @@ type StartOptions struct {
 	ShutdownFile string
 	// MetricsListenAddr is the address on which to expose the metrics endpoint.
 	MetricsListenAddr string
+	// MetricsPrefix is an optional Prometheus namespace prefix applied to all
+	// canary metric names.
+	MetricsPrefix string
+	// CanaryRequestTimeBuckets is an optional list of millisecond histogram
+	// bucket boundaries for the canary check duration metric.
+	CanaryRequestTimeBuckets []float64
 	// OperatorNamespace is the namespace the operator should watch for
 	// ingresscontroller resources.
 	OperatorNamespace string
@@ -71,6 +77,8 @@ func NewStartCommand() *cobra.Command {
 	cmd.Flags().StringVarP(&options.CanaryImage, "canary-image", "c", "", "image of the canary container that the operator will manage (optional)")
 	cmd.Flags().StringVarP(&options.ReleaseVersion, "release-version", "", statuscontroller.UnknownVersionValue, "the release version the operator should converge to (required)")
 	cmd.Flags().StringVarP(&options.MetricsListenAddr, "metrics-listen-addr", "", "127.0.0.1:60000", "metrics endpoint listen address (required)")
+	cmd.Flags().StringVarP(&options.MetricsPrefix, "metrics-prefix", "", "", "prometheus namespace prefix applied to all canary metric names (optional)")
+	cmd.Flags().Float64SliceVarP(&options.CanaryRequestTimeBuckets, "canary-request-time-buckets", "", nil, "millisecond histogram bucket boundaries for the canary check duration metric (optional)")
 	cmd.Flags().StringVarP(&options.ShutdownFile, "shutdown-file", "s", defaultTrustedCABundle, "if provided, shut down the operator when this file changes")
 
 	if err := cmd.MarkFlagRequired("namespace"); err != nil {
@@ -122,7 +130,14 @@ func start(opts *StartOptions) error {
 		CanaryImage:            opts.CanaryImage,
 	}
 
-	// Start operator metrics.
+	// Start operator metrics. Collector settings must be applied before
+	// the listener registers the collectors.
+	if len(opts.MetricsPrefix) != 0 {
+		canarycontroller.SetMetricsPrefix(opts.MetricsPrefix)
+	}
+	if len(opts.CanaryRequestTimeBuckets) != 0 {
+		canarycontroller.SetCanaryRequestTimeBuckets(opts.CanaryRequestTimeBuckets)
+	}
 	go canarycontroller.StartMetricsListener(opts.MetricsListenAddr, signal)
 
 	// Set up and start the file watcher.
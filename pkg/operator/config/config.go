@@ -14,6 +14,4 @@ type Config struct {
 
 	// CanaryImage is the ingress operator image, which runs a canary command.
 	CanaryImage string
-
-	Stop chan struct{}
 }
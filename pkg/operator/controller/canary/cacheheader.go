@@ -0,0 +1,54 @@
+package canary
+
+import (
+	"fmt"
+	"net/http"
+
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+// injectedCacheHeaders are response headers that indicate an
+// intermediary served or annotated the response from a cache. The
+// router is a plain proxy, so none of these should appear unless the
+// backend set them.
+var injectedCacheHeaders = []string{"Age", "X-Cache", "X-Cache-Lookup"}
+
+// probeCacheHeaders sends a probe to the canary route and verifies
+// that the router neither strips the Cache-Control header set by the
+// backend nor injects caching headers of its own.
+func (r *reconciler) probeCacheHeaders(route *routev1.Route) error {
+	options := r.probeOptionsForHost(route.Spec.Host)
+	client := r.newRouteProbeClient(route, options.Timeout)
+
+	response, err := client.Get(canaryRouteURL(routeProbeScheme(route), route.Spec.Host))
+	if err != nil {
+		return fmt.Errorf("error sending canary cache header probe request to %q: %v", route.Spec.Host, err)
+	}
+	response.Body.Close()
+
+	return checkCacheHeaders(response.Header, r.config.CacheHeaderProbeCacheControl)
+}
+
+// checkCacheHeaders verifies that the given response headers carry the
+// expected Cache-Control value and no caching headers that the backend
+// did not set. An empty expected value means the backend sets no
+// Cache-Control header, so any value present was injected by an
+// intermediary.
+func checkCacheHeaders(header http.Header, expectedCacheControl string) error {
+	cacheControl := header.Get("Cache-Control")
+	if len(expectedCacheControl) != 0 {
+		if cacheControl != expectedCacheControl {
+			return fmt.Errorf("expected canary response Cache-Control header to be %q, but got %q", expectedCacheControl, cacheControl)
+		}
+	} else if len(cacheControl) != 0 {
+		return fmt.Errorf("unexpected Cache-Control header %q injected into canary response", cacheControl)
+	}
+
+	for _, name := range injectedCacheHeaders {
+		if value := header.Get(name); len(value) != 0 {
+			return fmt.Errorf("unexpected caching header %s: %q injected into canary response", name, value)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package canary
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckCacheHeaders(t *testing.T) {
+	testCases := []struct {
+		description          string
+		headers              map[string]string
+		expectedCacheControl string
+		expectError          bool
+	}{
+		{
+			description:          "backend Cache-Control preserved",
+			headers:              map[string]string{"Cache-Control": "no-cache"},
+			expectedCacheControl: "no-cache",
+			expectError:          false,
+		},
+		{
+			description:          "backend Cache-Control stripped",
+			headers:              map[string]string{},
+			expectedCacheControl: "no-cache",
+			expectError:          true,
+		},
+		{
+			description:          "backend Cache-Control rewritten",
+			headers:              map[string]string{"Cache-Control": "max-age=60"},
+			expectedCacheControl: "no-cache",
+			expectError:          true,
+		},
+		{
+			description: "Cache-Control injected when the backend sets none",
+			headers:     map[string]string{"Cache-Control": "max-age=60"},
+			expectError: true,
+		},
+		{
+			description:          "Age header injected by an intermediary",
+			headers:              map[string]string{"Cache-Control": "no-cache", "Age": "30"},
+			expectedCacheControl: "no-cache",
+			expectError:          true,
+		},
+		{
+			description: "no caching headers at all",
+			headers:     map[string]string{},
+			expectError: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		header := http.Header{}
+		for name, value := range tc.headers {
+			header.Set(name, value)
+		}
+		err := checkCacheHeaders(header, tc.expectedCacheControl)
+		if tc.expectError && err == nil {
+			t.Errorf("%s: expected an error, but got none", tc.description)
+		} else if !tc.expectError && err != nil {
+			t.Errorf("%s: expected no error, but got: %v", tc.description, err)
+		}
+	}
+}
+
+func TestProbeCacheHeaders(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Cache-Control", "no-cache")
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	defer server.Close()
+
+	r := &reconciler{
+		config: Config{
+			CacheHeaderProbe:             true,
+			CacheHeaderProbeCacheControl: "no-cache",
+		},
+	}
+
+	route := routeForServer(server, "8080")
+	if err := r.probeCacheHeaders(route); err != nil {
+		t.Errorf("expected cache header probe to pass, but got: %v", err)
+	}
+
+	r.config.CacheHeaderProbeCacheControl = "max-age=300"
+	if err := r.probeCacheHeaders(route); err == nil {
+		t.Error("expected cache header probe to fail when the backend's Cache-Control does not match, but got no error")
+	}
+}
@@ -3,6 +3,9 @@ package canary
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/http"
+	"os"
 	"strconv"
 	"sync"
 	"time"
@@ -19,9 +22,12 @@ import (
 	routev1 "github.com/openshift/api/route/v1"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -38,11 +44,38 @@ const (
 	// canaryCheckFrequency is how long to wait in between canary checks.
 	canaryCheckFrequency = 1 * time.Minute
 	// canaryCheckCycleCount is how many successful canary checks should be observed
-	// before rotating the canary endpoint.
+	// before rotating the canary endpoint at the default poll interval.
 	canaryCheckCycleCount = 5
+	// canaryRotationCadence is roughly how often the canary route
+	// endpoint should be rotated, independent of the poll interval.
+	canaryRotationCadence = canaryCheckCycleCount * canaryCheckFrequency
 	// canaryCheckFailureCount is how many successive failing canary checks should
 	// be observed before the default ingress controller goes degraded.
 	canaryCheckFailureCount = 5
+	// defaultImageRolloutGracePeriod is how long probe failures are
+	// suppressed after a canary image update when
+	// Config.ImageRolloutGracePeriod is unset.
+	defaultImageRolloutGracePeriod = 3 * time.Minute
+	// apiReadTimeout bounds the API reads that the polling loop
+	// performs each cycle, so that a hung API server cannot block the
+	// polling goroutine indefinitely.
+	apiReadTimeout = 15 * time.Second
+	// defaultPollJitterFactor is the fraction of the polling interval
+	// by which each cycle's wait is randomly lengthened when
+	// Config.JitterFactor is unset, so that probes from many clusters
+	// or restarted controllers do not align into periodic load spikes
+	// on the router.
+	defaultPollJitterFactor = 0.1
+	// defaultWedgeThreshold is how many consecutive wrong-port echoes
+	// must be observed before the router is reported as wedged when
+	// Config.WedgeThreshold is unset.
+	defaultWedgeThreshold = 3
+	// defaultStartupGraceCycles is how many initial polling cycles a
+	// missing canary route is tolerated quietly when
+	// Config.StartupGraceCycles is unset. The reconcile loop usually
+	// creates the route within the first cycle, so a briefly-absent
+	// route at startup is expected rather than an error.
+	defaultStartupGraceCycles = 5
 
 	// CanaryRouteRotationAnnotation is an annotation on the default ingress controller
 	// that specifies whether or not the canary check loop should periodically rotate
@@ -52,11 +85,34 @@ const (
 	// a value of "true" (disabled otherwise).
 	CanaryRouteRotationAnnotation = "ingress.operator.openshift.io/rotate-canary-route"
 
+	// CanaryProbePathAnnotation is an annotation on the canary route
+	// that overrides the configured probe path for that route, for
+	// custom canary images that serve their health content at a
+	// subpath.
+	CanaryProbePathAnnotation = "ingress.operator.openshift.io/probe-path"
+
+	// CanaryProbeNowAnnotation is an annotation on the default ingress
+	// controller that triggers an immediate out-of-band canary probe
+	// whenever its value changes, so that an operator debugging an
+	// ingress issue need not wait out the polling interval.
+	CanaryProbeNowAnnotation = "ingress.operator.openshift.io/probe-canary-now"
+
 	// CanaryHealthcheckCommand is a parameter to pass to the ingress-operator to call
 	// into the handler for the canary daemonset health check
 	CanaryHealthcheckCommand = "serve-healthcheck"
 	// CanaryHealthcheckResponse is the message that signals a successful health check
 	CanaryHealthcheckResponse = "Healthcheck requested"
+
+	// CanaryHealthcheckPortsEnvName is the environment variable that the
+	// canary daemonset sets to tell the healthcheck server which ports to
+	// listen on when the service port count is overridden.
+	CanaryHealthcheckPortsEnvName = "CANARY_PORTS"
+
+	// canaryImageEnvName is the name of the environment variable on the
+	// operator's deployment that carries the canary image from the
+	// operator's release payload. It is used to resolve the canary image
+	// when Config.CanaryImage is unset.
+	canaryImageEnvName = "CANARY_IMAGE"
 )
 
 var (
@@ -69,9 +125,20 @@ var (
 // The canary controller will watch the Default IngressController, as well as
 // the canary service, daemonset, and route resources.
 func New(mgr manager.Manager, config Config) (controller.Controller, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid canary controller configuration: %w", err)
+	}
+
+	canaryImage, err := resolveCanaryImage(config.CanaryImage)
+	if err != nil {
+		return nil, err
+	}
+	config.CanaryImage = canaryImage
+
 	reconciler := &reconciler{
 		config:                    config,
 		client:                    mgr.GetClient(),
+		recorder:                  mgr.GetEventRecorderFor(canaryControllerName),
 		enableCanaryRouteRotation: false,
 	}
 	c, err := controller.New(canaryControllerName, mgr, controller.Options{Reconciler: reconciler})
@@ -79,6 +146,17 @@ func New(mgr manager.Manager, config Config) (controller.Controller, error) {
 		return nil, err
 	}
 
+	// Capture the manager's context so that the polling loop and its
+	// API calls are bounded by the manager's lifetime and shut down
+	// promptly when the manager stops.
+	if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		reconciler.setPollingContext(ctx)
+		<-ctx.Done()
+		return nil
+	})); err != nil {
+		return nil, err
+	}
+
 	// trigger reconcile requests for the canary controller via events for the default ingress controller.
 	defaultIcPredicate := predicate.NewPredicateFuncs(func(o client.Object) bool {
 		return o.GetName() == manifests.DefaultIngressControllerName
@@ -117,6 +195,75 @@ func New(mgr manager.Manager, config Config) (controller.Controller, error) {
 	return c, nil
 }
 
+// DesiredCanaryResources returns the fully-populated canary namespace,
+// daemonset, service, and route objects that the controller would
+// create for the given configuration, without applying them, so that
+// external tooling can render them for GitOps diffing or policy
+// review. Fields that are only known at runtime, such as the
+// daemonset's UID on the service's owner reference or the route's
+// admitted host, are left empty.
+func DesiredCanaryResources(config Config) ([]client.Object, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid canary controller configuration: %w", err)
+	}
+
+	canaryImage, err := resolveCanaryImage(config.CanaryImage)
+	if err != nil {
+		return nil, err
+	}
+	config.CanaryImage = canaryImage
+
+	// The desired* helpers hang off the reconciler, so build one that
+	// only carries the configuration and never touches the client.
+	r := &reconciler{config: config}
+
+	namespace := manifests.CanaryNamespace()
+	namespace.Name = r.canaryNamespace()
+
+	daemonset := desiredCanaryDaemonSet(config.CanaryImage, config.ResourceRequirements)
+	daemonset.Namespace = r.canaryNamespace()
+	if config.ServicePortCount > 0 {
+		setCanaryContainerPorts(daemonset, desiredCanaryPorts(config))
+	}
+	if len(config.ImagePullSecrets) != 0 {
+		daemonset.Spec.Template.Spec.ImagePullSecrets = config.ImagePullSecrets
+	}
+
+	trueVar := true
+	daemonsetRef := metav1.OwnerReference{
+		APIVersion: "apps/v1",
+		Kind:       "daemonset",
+		Name:       daemonset.Name,
+		Controller: &trueVar,
+	}
+	service := desiredCanaryService(daemonsetRef)
+	service.Namespace = r.canaryNamespace()
+	service.Spec.Ports = desiredCanaryPorts(config)
+
+	route, err := desiredCanaryRoute(config, service)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build canary route: %v", err)
+	}
+	route.Namespace = r.canaryNamespace()
+
+	return []client.Object{namespace, daemonset, service, route}, nil
+}
+
+// resolveCanaryImage returns the canary image to use. An explicitly
+// configured image takes precedence; otherwise the image is resolved
+// from the operator's release payload via the CANARY_IMAGE environment
+// variable set on the operator's deployment.
+func resolveCanaryImage(configured string) (string, error) {
+	if len(configured) != 0 {
+		return configured, nil
+	}
+	if image := os.Getenv(canaryImageEnvName); len(image) != 0 {
+		log.Info("resolved canary image from the operator's environment", "image", image)
+		return image, nil
+	}
+	return "", fmt.Errorf("canary image is not configured and %s is not set in the operator's environment", canaryImageEnvName)
+}
+
 func enqueueRequestForDefaultIngressController(namespace string) handler.EventHandler {
 	return handler.EnqueueRequestsFromMapFunc(
 		func(a client.Object) []reconcile.Request {
@@ -134,21 +281,97 @@ func enqueueRequestForDefaultIngressController(namespace string) handler.EventHa
 // Reconcile ensures that the canary controller's resources
 // are in the desired state.
 func (r *reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	result, err := r.reconcile(ctx, request)
+	// Under API server pressure, the reconcile's Get/Create/Update
+	// calls can be throttled. Requeue with a modest delay rather than
+	// surfacing an error and triggering aggressive error backoff.
+	if err != nil && isThrottlingError(err) {
+		log.Info("canary reconcile was throttled by the API server, requeueing", "error", err.Error())
+		return reconcile.Result{RequeueAfter: throttlingRequeueDelay}, nil
+	}
+	// Conflicts and server timeouts are transient: requeue with an
+	// increasing backoff rather than surfacing an error, so repeated
+	// transient failures do not hammer the API server. Terminal errors
+	// are returned as-is and use controller-runtime's default backoff.
+	if err != nil && isTransientAPIError(err) {
+		delay := r.nextTransientRequeueDelay()
+		log.Info("canary reconcile hit a transient API error, requeueing", "after", delay.String(), "error", err.Error())
+		return reconcile.Result{RequeueAfter: delay}, nil
+	}
+	r.resetTransientBackoff()
+	return result, err
+}
+
+// reconcile ensures the canary resources and annotation-driven state.
+func (r *reconciler) reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
 	result := reconcile.Result{}
 
+	// Get the default ingress controller before ensuring the canary
+	// resources: its annotations drive rotation and maintenance mode,
+	// and if it has been deleted the canary resources must be torn
+	// down rather than left orphaned.
+	ic := &operatorv1.IngressController{}
+	if err := r.client.Get(ctx, request.NamespacedName, ic); err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("default ingress controller not found, deleting canary resources", "name", request.NamespacedName.Name)
+			if err := r.ensureCanaryResourcesDeleted(ctx); err != nil {
+				return result, fmt.Errorf("failed to delete canary resources: %w", err)
+			}
+			return result, nil
+		}
+		return result, fmt.Errorf("failed to get ingress controller %s: %w", request.NamespacedName.Name, err)
+	}
+
+	// Tear down the canary and suspend probing entirely while the
+	// disable annotation is set; everything is restored on the next
+	// reconcile after the annotation is removed.
+	if canaryDisableRequested(ic) {
+		if !r.isCanaryDisabled() {
+			log.Info("canary is disabled by annotation, deleting canary resources")
+		}
+		r.setCanaryDisabled(true)
+		if err := r.ensureCanaryResourcesDeleted(ctx); err != nil {
+			return result, fmt.Errorf("failed to delete canary resources: %w", err)
+		}
+		return result, nil
+	}
+	r.setCanaryDisabled(false)
+
 	if _, _, err := r.ensureCanaryNamespace(); err != nil {
 		// Return if the canary namespace cannot be created since
 		// resource creation in a namespace that does not exist will fail.
-		return result, fmt.Errorf("failed to ensure canary namespace: %v", err)
+		CanaryReconcileErrors.WithLabelValues("namespace").Inc()
+		return result, fmt.Errorf("failed to ensure canary namespace: %w", err)
 	}
 
-	haveDs, daemonset, err := r.ensureCanaryDaemonSet()
+	if val, ok := ic.Annotations[CanaryRouteRotationAnnotation]; ok {
+		v, _ := strconv.ParseBool(val)
+		r.mu.Lock()
+		r.enableCanaryRouteRotation = v
+		r.mu.Unlock()
+	}
+
+	maintenance := false
+	if val, ok := ic.Annotations[CanaryMaintenanceModeAnnotation]; ok {
+		maintenance, _ = strconv.ParseBool(val)
+	}
+	r.setMaintenanceMode(maintenance)
+
+	haveDs, daemonset, err := r.ensureCanaryDaemonSet(ctx)
 	if err != nil {
-		return result, fmt.Errorf("failed to ensure canary daemonset: %v", err)
+		CanaryReconcileErrors.WithLabelValues("daemonset").Inc()
+		return result, fmt.Errorf("failed to ensure canary daemonset: %w", err)
 	} else if !haveDs {
+		CanaryReconcileErrors.WithLabelValues("daemonset").Inc()
 		return result, fmt.Errorf("failed to get canary daemonset: %v", err)
 	}
 
+	// Expose the deployed canary image so that body-mismatch failures
+	// can be correlated with image overrides.
+	if len(daemonset.Spec.Template.Spec.Containers) > 0 {
+		SetCanaryInfoMetric(daemonset.Spec.Template.Spec.Containers[0].Image)
+	}
+
 	trueVar := true
 	daemonsetRef := metav1.OwnerReference{
 		APIVersion: "apps/v1",
@@ -158,39 +381,73 @@ func (r *reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 		Controller: &trueVar,
 	}
 
-	haveService, service, err := r.ensureCanaryService(daemonsetRef)
+	haveService, service, err := r.ensureCanaryService(ctx, daemonsetRef)
 	if err != nil {
-		return result, fmt.Errorf("failed to ensure canary service: %v", err)
+		CanaryReconcileErrors.WithLabelValues("service").Inc()
+		return result, fmt.Errorf("failed to ensure canary service: %w", err)
 	} else if !haveService {
+		CanaryReconcileErrors.WithLabelValues("service").Inc()
 		return result, fmt.Errorf("failed to get canary service: %v", err)
 	}
+	// Surface how many ports are available for rotation so that an
+	// alert can fire when rotation and wedge detection are inactive.
+	SetCanaryAvailableRotationPortsMetric(len(service.Spec.Ports))
+
+	// The daemonset, the service, and the rotation logic all derive
+	// their port lists from desiredCanaryPorts, but verify that the
+	// live objects agree so that probes never target a service port
+	// with no listener behind it.
+	if err := canaryPortsAligned(daemonset, service); err != nil {
+		return result, fmt.Errorf("canary daemonset and service ports are misaligned: %w", err)
+	}
 
-	haveRoute, route, err := r.ensureCanaryRoute(service)
+	haveRoute, route, err := r.ensureCanaryRoute(ctx, service)
 	if err != nil {
-		return result, fmt.Errorf("failed to ensure canary route: %v", err)
+		CanaryReconcileErrors.WithLabelValues("route").Inc()
+		return result, fmt.Errorf("failed to ensure canary route: %w", err)
 	} else if !haveRoute {
+		CanaryReconcileErrors.WithLabelValues("route").Inc()
 		return result, fmt.Errorf("failed to get canary route: %v", err)
 	}
 
-	// Get the canary route rotation annotation value
-	// from the default ingress controller.
-	ic := &operatorv1.IngressController{}
-	if err := r.client.Get(context.TODO(), request.NamespacedName, ic); err != nil {
-		return result, fmt.Errorf("failed to get ingress controller %s: %v", request.NamespacedName.Name, err)
+	// If the default ingress controller's domain has changed, the
+	// existing canary route's host is stale and the route must be
+	// recreated so that it is admitted under the new domain.
+	haveRoute, route, err = r.ensureCanaryRouteHost(ctx, route, ic.Status.Domain, service)
+	if err != nil {
+		CanaryReconcileErrors.WithLabelValues("route").Inc()
+		return result, fmt.Errorf("failed to ensure canary route host: %w", err)
+	} else if !haveRoute {
+		CanaryReconcileErrors.WithLabelValues("route").Inc()
+		return result, fmt.Errorf("failed to get canary route: %v", err)
 	}
 
-	if val, ok := ic.Annotations[CanaryRouteRotationAnnotation]; ok {
-		v, _ := strconv.ParseBool(val)
-		r.mu.Lock()
-		r.enableCanaryRouteRotation = v
-		r.mu.Unlock()
+	// Record the canary route's current insecure edge termination
+	// policy so that drift from the expected policy is visible.
+	SetCanaryRouteInsecurePolicyMetric(route)
+
+	if err := r.ensureSingleCanaryRoute(ctx); err != nil {
+		return result, fmt.Errorf("failed to ensure single canary route: %w", err)
+	}
+
+	// Surface the effective probe configuration on the canary route
+	// for debugging.
+	if err := r.ensureEffectiveConfigAnnotation(route); err != nil {
+		return result, fmt.Errorf("failed to ensure canary effective config annotation: %w", err)
+	}
+
+	// Run an immediate out-of-band probe when the on-demand trigger
+	// annotation changes. The probe runs off the reconcile goroutine
+	// so that a slow probe does not stall other reconciles.
+	if r.consumeProbeTrigger(ic) && checkRouteAdmitted(route) {
+		go r.runOnDemandProbe(r.pollingContext(ctx), route)
 	}
 
 	// Start probing the canary route once the canary route
 	// has been admitted.
 	if checkRouteAdmitted(route) {
 		routeProbeRunner.Do(func() {
-			r.startCanaryRoutePolling(r.config.Stop)
+			r.startCanaryRoutePolling(r.pollingContext(ctx))
 		})
 	}
 
@@ -201,7 +458,373 @@ func (r *reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 type Config struct {
 	Namespace   string
 	CanaryImage string
-	Stop        chan struct{}
+
+	// MaxProbeRedirects is the maximum number of redirects a canary
+	// probe follows before failing. Defaults to 3 when unset.
+	MaxProbeRedirects int
+	// RecordRedirectChain specifies whether canary probes record each
+	// redirect hop in the probe result for debugging.
+	RecordRedirectChain bool
+	// CompressionProbe specifies whether canary probes request a
+	// gzipped response and validate that the response decompresses
+	// to the expected body.
+	CompressionProbe bool
+	// ExtraProbeTargets is a list of additional endpoints that the
+	// canary polling loop probes each cycle, in addition to the canary
+	// route. Each target carries its own TLS settings and expected
+	// body, so a mix of http and https targets with different CAs can
+	// be probed.
+	ExtraProbeTargets []ProbeTarget
+	// ExtraProbeJitterFraction is the fraction of the polling interval
+	// across which extra-target probes are spread to avoid firing them
+	// all at the exact cycle start. Defaults to 0.5 when unset.
+	ExtraProbeJitterFraction float64
+	// ValidateDateHeader specifies whether canary probes verify that
+	// the response's Date header is present and within MaxDateSkew of
+	// the operator's clock.
+	ValidateDateHeader bool
+	// MaxDateSkew is the maximum allowed skew between the response's
+	// Date header and the operator's clock when ValidateDateHeader is
+	// enabled. Defaults to 5 minutes when unset.
+	MaxDateSkew time.Duration
+	// MaxLatency is a latency SLO for canary checks: a probe whose
+	// total measured time exceeds this threshold marks the route
+	// unreachable even when the request itself succeeds. Disabled
+	// when unset.
+	MaxLatency time.Duration
+	// EnableInsightsSummary specifies whether the polling loop writes
+	// an aggregate canary health summary to a ConfigMap each cycle for
+	// gathering by the insights operator.
+	EnableInsightsSummary bool
+	// InsightsSummaryConfigMapName is the name of the ConfigMap in the
+	// canary namespace that the health summary is written to. Defaults
+	// to "canary-health-summary" when unset.
+	InsightsSummaryConfigMapName string
+	// HostProbeOverrides maps a host to probe settings that override
+	// the global defaults when probing that host. Hosts without an
+	// entry use the defaults.
+	HostProbeOverrides map[string]ProbeOptions
+	// ServiceProbe specifies whether the polling loop also probes the
+	// canary service's ClusterIP directly each cycle, bypassing the
+	// router, so that failures can be localized to the router versus
+	// the canary pods.
+	ServiceProbe bool
+	// BalanceProbe specifies whether the polling loop validates that
+	// the router honors the balance algorithm that the canary route is
+	// annotated with.
+	BalanceProbe bool
+	// BalanceProbeRequests is how many requests a balance probe sends
+	// when observing the backend distribution. Defaults to 10 when
+	// unset.
+	BalanceProbeRequests int
+	// ImageRolloutGracePeriod is how long probe failures are suppressed
+	// after a canary image update while the resulting rollout settles.
+	// Defaults to 3 minutes when unset.
+	ImageRolloutGracePeriod time.Duration
+	// ProbeEventWriter, when set, receives one structured JSON line
+	// per probe outcome, independent of the operator's logger backend.
+	ProbeEventWriter io.Writer
+	// CanaryFinalizer, when set, is added to the canary resources that
+	// the reconciler creates so that teardown is orchestrated by the
+	// reconciler rather than racing garbage collection.
+	CanaryFinalizer string
+	// PhaseBudgets holds per-phase timing budgets for probes. A probe
+	// whose measured DNS, connect, TLS, TTFB, or body-read phase
+	// exceeds its budget fails with a PhaseBudgetError identifying the
+	// slow phase. Zero-valued budgets are unlimited.
+	PhaseBudgets PhaseBudgets
+	// Verbose specifies whether each probe logs its per-phase timing
+	// breakdown (DNS, TCP, TLS, server processing, content transfer)
+	// along with the response status and body length. The verbose lines
+	// are logged at an elevated verbosity level, so they are quiet
+	// unless the operator's logger is configured to show them.
+	Verbose bool
+	// NegativeHostProbe specifies whether the polling loop verifies
+	// that the router rejects requests for an unknown Host header
+	// rather than leaking a default backend response.
+	NegativeHostProbe bool
+	// TraceExemplars specifies whether each probe sends a W3C
+	// traceparent header and attaches the trace ID to its latency
+	// observation as an OpenMetrics exemplar, linking latency spikes
+	// directly to traces.
+	TraceExemplars bool
+	// SlowHeaderProbe specifies whether the polling loop verifies that
+	// the router closes connections with slowly-trickled request
+	// headers (slow-loris protection) rather than holding them open.
+	SlowHeaderProbe bool
+	// SlowHeaderProbeWindow is how long the router is given to close a
+	// slow-header connection before the probe fails. Defaults to 30
+	// seconds when unset.
+	SlowHeaderProbeWindow time.Duration
+	// ProbeQuorumFraction is the fraction of probes in a cycle (the
+	// canary route probe plus any extra targets) that must succeed for
+	// the canary to be declared reachable. Zero requires every probe
+	// to succeed.
+	ProbeQuorumFraction float64
+	// ProbeTargetIP, when set, is the IP address that probes connect
+	// to instead of resolving the route host via DNS. The route host
+	// is still sent as the Host header and TLS SNI, isolating the load
+	// balancer and router path from name resolution.
+	ProbeTargetIP string
+	// ProbeSourceAddress, when set, is the local IP address that probe
+	// connections bind to before dialing out. On multi-homed nodes this
+	// makes probe traffic egress over the same interface as production
+	// traffic. When unset, the OS chooses the source address.
+	ProbeSourceAddress string
+	// PipeliningProbe specifies whether the polling loop verifies that
+	// the router handles pipelined HTTP/1.1 requests safely, either
+	// serializing the responses or closing the connection, rather than
+	// corrupting the response stream.
+	PipeliningProbe bool
+	// MethodEchoProbe specifies whether the polling loop verifies that
+	// non-GET request methods round-trip through the router unchanged.
+	MethodEchoProbe bool
+	// MethodEchoProbeMethods is the list of methods a method echo
+	// probe sends. Defaults to POST, PUT, and DELETE when unset.
+	MethodEchoProbeMethods []string
+	// AcceptableStatusCodes is the set of HTTP response status codes a
+	// probe accepts as a successful canary check, for canary images or
+	// intermediaries that legitimately respond with codes other than
+	// 200. Defaults to accepting only 200 when unset.
+	AcceptableStatusCodes []int
+	// RouteWildcardPolicy is the wildcard policy to set on the desired
+	// canary route, so that the canary can validate wildcard admission
+	// for clusters that serve *.apps domains. When unset, the route
+	// manifest's policy (no wildcard) is kept.
+	RouteWildcardPolicy routev1.WildcardPolicyType
+	// RouteSubdomain is the subdomain to set on the desired canary
+	// route. When set, the route's host is left for the router to
+	// assign under the subdomain and probes target the admitted host.
+	// When unset, the API server assigns the host as before.
+	RouteSubdomain string
+	// StartupGraceCycles is how many initial polling cycles a missing
+	// canary route is logged quietly at Info level rather than as an
+	// error. Defaults to 5 when unset.
+	StartupGraceCycles int
+	// IdleTimeoutProbe specifies whether the polling loop verifies
+	// that the router closes idle keep-alive connections rather than
+	// leaking them.
+	IdleTimeoutProbe bool
+	// IdleTimeoutProbeWindow is how long an idle-timeout probe leaves
+	// its connection idle before attempting reuse. It should exceed
+	// the router's configured idle timeout. Defaults to 5 minutes when
+	// unset.
+	IdleTimeoutProbeWindow time.Duration
+	// ProbeResultHooks are callbacks invoked after each probe with the
+	// probe's outcome, letting embedders wire custom side effects
+	// (paging, additional metrics) without forking. Hooks run on their
+	// own goroutines with panic recovery.
+	ProbeResultHooks []ProbeResultHook
+	// H2NoPushProbe specifies whether the polling loop verifies that
+	// the router does not attempt HTTP/2 server push.
+	H2NoPushProbe bool
+	// SuccessRatioWindow is how many recent probe results the canary
+	// success ratio gauge is computed over. Defaults to
+	// defaultSuccessRatioWindow when unset.
+	SuccessRatioWindow int
+	// RotationEveryNChecks is how many successful canary checks are
+	// observed between canary route endpoint rotations when rotation
+	// is enabled. When unset, the threshold is derived from the poll
+	// interval so that the rotation cadence stays roughly constant.
+	RotationEveryNChecks int
+	// RotationSettleCycles is how many polling cycles are skipped
+	// after a route rotation to give the router time to reload before
+	// probing resumes. Defaults to 1 when unset, preserving the
+	// behavior of skipping only the rotation cycle itself.
+	RotationSettleCycles int
+	// RotationSettleDelay is how long probes are suppressed after a
+	// route rotation to give the router time to reload, independent of
+	// the polling cadence. When set, it takes precedence over
+	// RotationSettleCycles.
+	RotationSettleDelay time.Duration
+	// RotationPorts restricts port rotation to the given target port
+	// subset, intersected with the canary service's actual ports.
+	// Rotation uses every service port when unset or when none of the
+	// given ports exist on the service.
+	RotationPorts []intstr.IntOrString
+	// JitterFactor is the fraction of the polling interval by which
+	// each cycle's wait is randomly lengthened, so that probe timing
+	// does not synchronize across clusters or controller restarts.
+	// Defaults to defaultPollJitterFactor when unset.
+	JitterFactor float64
+	// PollInterval is how long the polling loop waits between canary
+	// checks. Defaults to canaryCheckFrequency when unset. The route
+	// rotation threshold is derived from this value so the rotation
+	// cadence stays roughly constant.
+	PollInterval time.Duration
+	// ProbeMethod is the HTTP method that canary probes use. Defaults
+	// to GET when unset; custom canary images may validate other
+	// methods.
+	ProbeMethod string
+	// ProbePath is the URL path that canary probes request, for custom
+	// canary images that serve their health response on a specific
+	// path. Defaults to "/" when unset.
+	ProbePath string
+	// ProbeBody is an optional request body sent with canary probes,
+	// for custom canary images that validate request payloads.
+	ProbeBody string
+	// ExpectedBody is the string that canary probes expect the
+	// response body to contain, for clusters that override CanaryImage
+	// with an image returning a different body. Defaults to
+	// CanaryHealthcheckResponse when empty. An empty response body is
+	// still treated as a failure regardless of this value.
+	ExpectedBody string
+	// MaxProbeRetries is how many attempts a canary check makes, with
+	// exponential backoff between them, before declaring the route
+	// unreachable. Defaults to defaultMaxProbeRetries when unset.
+	MaxProbeRetries int
+	// MaxCreateRetries is how many attempts a canary resource creation
+	// makes within a single reconcile, with exponential backoff between
+	// them, when the API server fails transiently. Defaults to
+	// defaultMaxCreateRetries when unset.
+	MaxCreateRetries int
+	// CreateRetryBaseDelay is the delay before the first creation
+	// retry; the delay doubles after each failed attempt. Defaults to
+	// defaultCreateRetryBaseDelay when unset.
+	CreateRetryBaseDelay time.Duration
+	// ProbeTimeout is the overall timeout for a single canary probe
+	// request. Defaults to defaultProbeTimeout when unset. Per-host
+	// overrides in HostProbeOverrides take precedence.
+	ProbeTimeout time.Duration
+	// ProbeRouterCABundle is a PEM-encoded CA bundle used to verify
+	// the router's serving certificate when probing TLS-terminated
+	// canary routes. When empty, certificate verification is skipped
+	// because the default router certificate may be self signed.
+	ProbeRouterCABundle []byte
+	// ProbeRouterCABundleConfigMap is the name of a ConfigMap in the
+	// openshift-config namespace whose "ca-bundle.crt" key holds a
+	// PEM-encoded CA bundle for verifying the router's serving
+	// certificate. It is consulted when ProbeRouterCABundle is empty.
+	ProbeRouterCABundleConfigMap string
+	// ProbeInsecureSkipVerify explicitly disables certificate
+	// verification for canary probes regardless of any configured CA
+	// bundle, as an escape hatch for development clusters.
+	ProbeInsecureSkipVerify bool
+	// EnableIntrospection specifies whether a read-only HTTP/JSON
+	// endpoint serving the reconciler's internal state is bound to
+	// localhost for advanced debugging.
+	EnableIntrospection bool
+	// IntrospectionAddr is the localhost address that the
+	// introspection endpoint binds to. Defaults to
+	// defaultIntrospectionAddr when unset.
+	IntrospectionAddr string
+	// ResourceRequirements, when set, overrides the canary container's
+	// resource requests and limits from the manifest, so the canary
+	// can be right-sized for constrained clusters or clusters with
+	// strict LimitRanges.
+	ResourceRequirements corev1.ResourceRequirements
+	// ImagePullSecrets are references to secrets in the canary
+	// namespace used for pulling the canary image from registries that
+	// require credentials not available to the default service
+	// account. Empty by default.
+	ImagePullSecrets []corev1.LocalObjectReference
+	// WedgeThreshold is how many consecutive wrong-port echoes must be
+	// observed before the router is reported as wedged through the
+	// router-wedged gauge. A single echo can be a transient artifact of
+	// a settling reload, so the threshold defaults to
+	// defaultWedgeThreshold when unset.
+	WedgeThreshold int
+	// StableMetricHostLabel, when set, replaces the raw route host in
+	// the canary latency histograms' host label (e.g. with the shard
+	// name), so that series counts stay bounded even if the route host
+	// changes across recreations.
+	StableMetricHostLabel string
+	// ServicePortCount is how many ports the canary service and the
+	// canary container expose. Defaults to the manifest's port list
+	// when unset. Port rotation toggles the route between these ports
+	// to detect a wedged router, so more ports widen wedge-detection
+	// coverage; rotation is disabled when fewer than two ports are
+	// exposed.
+	ServicePortCount int
+	// CanaryNamespace, when set, is the namespace that the canary
+	// resources are managed in instead of the default canary
+	// namespace, for testing or for clusters with namespace naming
+	// policies.
+	CanaryNamespace string
+	// PortEchoHeader is the name of the response header that the
+	// canary backend echoes the request port in, for customized canary
+	// images that use a different header name. Defaults to
+	// echoServerPortAckHeader when unset.
+	PortEchoHeader string
+	// UserAgent is the User-Agent header value that canary probes send,
+	// so that canary traffic is identifiable in router and backend
+	// access logs. Defaults to defaultProbeUserAgent when unset.
+	UserAgent string
+	// FailureThreshold is how many consecutive canary check failures
+	// must be observed before the route's reachability gauge flips to
+	// unreachable. Defaults to 1 when unset, preserving the behavior
+	// of flipping on the first failure. Any success resets the count.
+	FailureThreshold int
+	// DegradedThreshold is how many successive canary check failures
+	// must be observed before the CanaryChecksSucceeding condition on
+	// the default ingress controller flips to False. Defaults to
+	// canaryCheckFailureCount when unset. The condition recovers on
+	// the first successful check.
+	DegradedThreshold int
+	// FailureLogWindow is how long an unchanged canary check failure
+	// reason is suppressed after being logged once, so that a
+	// long-lived outage does not bury other operator logs in identical
+	// error lines. The reason is logged again when it changes, when the
+	// canary recovers, or when the window elapses. Defaults to
+	// defaultFailureLogWindow when unset. Metrics still update on every
+	// cycle.
+	FailureLogWindow time.Duration
+	// CacheHeaderProbe specifies whether the polling loop verifies
+	// that the router preserves the backend's Cache-Control header
+	// and injects no caching headers of its own.
+	CacheHeaderProbe bool
+	// CacheHeaderProbeCacheControl is the Cache-Control value that the
+	// canary backend is expected to set. An empty value means the
+	// backend sets no Cache-Control header.
+	CacheHeaderProbeCacheControl string
+	// ExpectContinueProbe specifies whether the polling loop verifies
+	// that requests with Expect: 100-continue complete even when the
+	// interim 100 response is withheld and the client falls back to
+	// sending the body.
+	ExpectContinueProbe bool
+}
+
+// Validate returns an error if the configuration is unusable. Checking
+// up front turns confusing runtime failures deep in reconcile or the
+// polling loop into a clear error at operator startup. The canary image
+// is not checked here since it may still be resolved from the
+// operator's environment.
+func (c Config) Validate() error {
+	if len(c.Namespace) == 0 {
+		return fmt.Errorf("Namespace is required")
+	}
+	if c.PollInterval < 0 {
+		return fmt.Errorf("PollInterval must not be negative, got %v", c.PollInterval)
+	}
+	if c.JitterFactor < 0 {
+		return fmt.Errorf("JitterFactor must not be negative, got %v", c.JitterFactor)
+	}
+	if c.ProbeQuorumFraction < 0 || c.ProbeQuorumFraction > 1 {
+		return fmt.Errorf("ProbeQuorumFraction must be between 0 and 1, got %v", c.ProbeQuorumFraction)
+	}
+	if c.ExtraProbeJitterFraction < 0 || c.ExtraProbeJitterFraction > 1 {
+		return fmt.Errorf("ExtraProbeJitterFraction must be between 0 and 1, got %v", c.ExtraProbeJitterFraction)
+	}
+	if c.ServicePortCount < 0 {
+		return fmt.Errorf("ServicePortCount must not be negative, got %d", c.ServicePortCount)
+	}
+	if c.FailureThreshold < 0 {
+		return fmt.Errorf("FailureThreshold must not be negative, got %d", c.FailureThreshold)
+	}
+	if c.DegradedThreshold < 0 {
+		return fmt.Errorf("DegradedThreshold must not be negative, got %d", c.DegradedThreshold)
+	}
+	for _, code := range c.AcceptableStatusCodes {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("AcceptableStatusCodes must be valid HTTP status codes, got %d", code)
+		}
+	}
+	switch c.RouteWildcardPolicy {
+	case "", routev1.WildcardPolicyNone, routev1.WildcardPolicySubdomain:
+	default:
+		return fmt.Errorf("RouteWildcardPolicy must be %q or %q, got %q", routev1.WildcardPolicyNone, routev1.WildcardPolicySubdomain, c.RouteWildcardPolicy)
+	}
+	return nil
 }
 
 // reconciler handles the actual canary reconciliation logic in response to
@@ -211,10 +834,131 @@ type reconciler struct {
 
 	client client.Client
 
+	// recorder emits Kubernetes events on the canary route when canary
+	// checks transition between passing and failing.
+	recorder record.EventRecorder
+
 	// Use a mutex so enableCanaryRotation is
 	// go-routine safe.
 	mu                        sync.Mutex
 	enableCanaryRouteRotation bool
+
+	// rotationMu serializes canary route rotations so that concurrent
+	// rotations (e.g. from multiple reconcile workers and the polling
+	// loop) cannot interleave and leave the route on an unexpected port.
+	rotationMu sync.Mutex
+
+	// disabledMu guards canaryDisabled, which is written by the
+	// reconcile loop and read by the polling goroutine.
+	disabledMu sync.Mutex
+	// canaryDisabled is true while the canary is disabled by annotation
+	// and the canary resources are torn down.
+	canaryDisabled bool
+
+	// maintenanceMu guards maintenanceMode, which is written by the
+	// reconcile loop and read by the polling goroutine.
+	maintenanceMu sync.Mutex
+	// maintenanceMode is true while the canary daemonset is scaled to
+	// zero for maintenance and canary checks are suspended.
+	maintenanceMode bool
+
+	// imageRolloutMu guards lastImageRollout, which is written by the
+	// reconcile loop and read by the polling goroutine.
+	imageRolloutMu sync.Mutex
+	// lastImageRollout is when the canary image was last updated.
+	lastImageRollout time.Time
+
+	// reuseMu guards justRotated and reuseClient.
+	reuseMu sync.Mutex
+	// justRotated is true if the route target port was rotated since
+	// the last probe, so that a wrong-port echo can be classified as a
+	// connection-reuse leak versus a router wedge.
+	justRotated bool
+	// reuseClient is a persistent keep-alive client used to detect
+	// connections pinned to the pre-rotation backend.
+	reuseClient *http.Client
+
+	// successRatioMu guards successWindow.
+	successRatioMu sync.Mutex
+	// successWindow is a sliding window of recent probe outcomes used
+	// to compute the canary success ratio gauge.
+	successWindow []bool
+
+	// introspectMu guards stats and recentResults, which are written
+	// by the polling goroutine and read by the introspection server.
+	introspectMu sync.Mutex
+	// stats aggregates canary probe outcomes for the insights health
+	// summary and the introspection endpoint.
+	stats probeStats
+	// recentResults is a ring buffer of the most recent probe
+	// outcomes, retained for the introspection endpoint.
+	recentResults []probeRecord
+
+	// lastFailureReason is the most recently logged canary check
+	// failure reason and lastFailureLogTime is when it was logged, used
+	// to suppress repeated identical failure logs within the failure
+	// log window. They are only accessed from the polling goroutine.
+	lastFailureReason  string
+	lastFailureLogTime time.Time
+
+	// lastProbeTrigger is the most recently handled value of the
+	// on-demand probe annotation. It is only accessed from the
+	// reconcile goroutine.
+	lastProbeTrigger string
+
+	// consecutiveWrongPortEchoes counts successive probes whose
+	// request-port echo did not match the route's target port, driving
+	// wedged-router detection. It is only accessed from the polling
+	// goroutine.
+	consecutiveWrongPortEchoes int
+
+	// transientFailures counts successive reconciles that failed with
+	// a transient API error, driving the requeue backoff. It is only
+	// accessed from the reconcile goroutine.
+	transientFailures int
+
+	// pollingCtxMu guards pollingCtx.
+	pollingCtxMu sync.Mutex
+	// pollingCtx is the manager-derived context that bounds the
+	// polling loop. It is set when the manager starts.
+	pollingCtx context.Context
+}
+
+// setPollingContext records the manager-derived context that bounds
+// the polling loop.
+func (r *reconciler) setPollingContext(ctx context.Context) {
+	r.pollingCtxMu.Lock()
+	defer r.pollingCtxMu.Unlock()
+	r.pollingCtx = ctx
+}
+
+// pollingContext returns the manager-derived context, or the given
+// fallback context if the manager's context has not been recorded yet.
+func (r *reconciler) pollingContext(fallback context.Context) context.Context {
+	r.pollingCtxMu.Lock()
+	defer r.pollingCtxMu.Unlock()
+	if r.pollingCtx != nil {
+		return r.pollingCtx
+	}
+	return fallback
+}
+
+// recordCanaryCheckEvent emits a Kubernetes event on the canary route
+// when a canary check transitions between passing and failing, so the
+// transition is discoverable through normal kubectl workflows rather
+// than only in operator logs. A failing check is only a transition on
+// the first failure after a passing check, and a passing check is only
+// a transition when it ends a run of failures.
+func (r *reconciler) recordCanaryCheckEvent(route *routev1.Route, successiveFail int, err error) {
+	if r.recorder == nil {
+		return
+	}
+	switch {
+	case err != nil && successiveFail == 1:
+		r.recorder.Eventf(route, "Warning", "CanaryCheckFailing", "canary check for route host %q failed: %v", route.Spec.Host, err)
+	case err == nil && successiveFail > 0:
+		r.recorder.Eventf(route, "Normal", "CanaryCheckRecovered", "canary check for route host %q succeeded after %d consecutive failures", route.Spec.Host, successiveFail)
+	}
 }
 
 func (r *reconciler) isCanaryRouteRotationEnabled() bool {
@@ -223,7 +967,13 @@ func (r *reconciler) isCanaryRouteRotationEnabled() bool {
 	return r.enableCanaryRouteRotation
 }
 
-func (r *reconciler) startCanaryRoutePolling(stop <-chan struct{}) error {
+func (r *reconciler) startCanaryRoutePolling(ctx context.Context) error {
+	// Optionally expose the reconciler's internal state on localhost
+	// for debugging.
+	if r.config.EnableIntrospection {
+		r.startIntrospectionServer(ctx)
+	}
+
 	// Keep track of how many canary checks have passed
 	// so the route endpoint can be periodically cycled
 	// (when canary route rotation is enabled).
@@ -233,24 +983,118 @@ func (r *reconciler) startCanaryRoutePolling(stop <-chan struct{}) error {
 	// for status reporting.
 	successiveFail := 0
 
-	go wait.Until(func() {
+	// Keep track of how many polling cycles have run so that a
+	// briefly-absent route at startup can be tolerated quietly.
+	pollCycle := 0
+
+	// Probe at the configured interval, deriving the rotation
+	// threshold from it so that the ~5 minute rotation cadence is
+	// preserved regardless of how aggressively the route is polled.
+	pollInterval := r.config.PollInterval
+	if pollInterval == 0 {
+		pollInterval = canaryCheckFrequency
+	}
+	rotationThreshold := r.rotationThreshold(pollInterval)
+
+	// Post-rotation quiet period during which probes are suppressed
+	// while the router settles.
+	settle := rotationSettle{}
+
+	// Rotation needs at least two service ports to toggle between.
+	rotationPossible := len(desiredCanaryPorts(r.config)) >= 2
+
+	// Jitter each cycle's wait so that probes do not synchronize
+	// across clusters or controller restarts.
+	go superviseCanaryRoutePolling(ctx, func(ctx context.Context) {
+		pollCycle++
+		// Record a heartbeat before anything that can skip the cycle,
+		// so that a stalled poller is distinguishable from one that is
+		// merely skipping checks.
+		SetCanaryPollerHeartbeatMetric(time.Now())
+		// Suspend canary checks entirely while the canary is disabled
+		// by annotation; the canary resources are torn down, so any
+		// probe would fail spuriously.
+		if r.isCanaryDisabled() {
+			log.Info("canary checks suspended while the canary is disabled by annotation")
+			return
+		}
+		// Suspend canary checks while maintenance mode is enabled;
+		// the canary daemonset is scaled to zero, so probe failures
+		// would be spurious.
+		if r.inMaintenanceMode() {
+			log.Info("canary checks suspended while maintenance mode is enabled")
+			return
+		}
+
+		// Skip cycles while the router settles after a rotation.
+		if r.inRotationSettle(&settle, time.Now()) {
+			log.Info("waiting for the router to settle after canary route rotation")
+			return
+		}
+
+		// Skip probing until the canary daemonset has available pods;
+		// probe failures before the canary pods are serving would be
+		// spurious, and saying "not ready yet" distinguishes a
+		// settling rollout from an actually broken data path.
+		haveDaemonset, daemonset, err := r.currentCanaryDaemonSet(ctx)
+		if err != nil {
+			log.Error(err, "failed to get canary daemonset for canary check")
+			return
+		} else if !haveDaemonset || !canaryDaemonSetReady(daemonset) {
+			log.Info("canary daemonset does not have available pods yet, skipping canary check")
+			return
+		}
+
 		// Get the current canary route every iteration in case it has been modified
-		haveRoute, route, err := r.currentCanaryRoute()
+		haveRoute, route, err := r.currentCanaryRoute(ctx)
 		if err != nil {
 			log.Error(err, "failed to get current canary route for canary check")
 			return
 		} else if !haveRoute {
-			log.Info("canary check route does not exist")
+			if inStartupGrace(pollCycle, r.config.StartupGraceCycles) {
+				log.Info("canary check route does not exist yet, waiting for it to be created")
+			} else {
+				log.Error(fmt.Errorf("canary route still does not exist after %d canary check cycles", pollCycle), "canary check route does not exist")
+			}
+			return
+		}
+
+		// Skip probing until the route has been admitted by the default
+		// ingress controller; an unadmitted route has no working host,
+		// so probe failures (DNS in particular) would be spurious right
+		// after route creation or during a router rollout.
+		if !checkRouteAdmitted(route) {
+			log.Info("canary route is not admitted yet, skipping canary check", "namespace", route.Namespace, "name", route.Name)
 			return
 		}
 
+		// Probe any additional labeled canary routes (e.g. per-shard
+		// canary routes) so that each records its own reachability,
+		// independent of the primary route's result.
+		if routes, err := r.listCanaryRoutes(ctx); err != nil {
+			log.Error(err, "failed to list canary routes for canary check")
+		} else {
+			r.probeSecondaryCanaryRoutes(ctx, routes, route.Name)
+		}
+
+		// Optionally probe the canary service's ClusterIP directly;
+		// comparing its result with the route probe localizes failures
+		// to the router versus the canary pods.
+		if r.config.ServiceProbe {
+			r.probeCanaryService(ctx)
+		}
+
 		// Check if canary route rotations are enabled every iteration.
 		rotationEnabled := r.isCanaryRouteRotationEnabled()
+		if rotationEnabled && !rotationPossible {
+			log.Info("canary route rotation is enabled, but the canary service exposes fewer than two ports; rotation is disabled")
+			rotationEnabled = false
+		}
 
 		// Periodically rotate the canary route endpoint if
 		// rotationEnabled is true.
-		if rotationEnabled && checkCount > canaryCheckCycleCount {
-			haveService, service, err := r.currentCanaryService()
+		if rotationEnabled && checkCount > rotationThreshold {
+			haveService, service, err := r.currentCanaryService(ctx)
 			if err != nil {
 				log.Error(err, "failed to get canary service")
 				return
@@ -258,32 +1102,219 @@ func (r *reconciler) startCanaryRoutePolling(stop <-chan struct{}) error {
 				log.Info("canary check service does not exist")
 				return
 			}
-			route, err = r.rotateRouteEndpoint(service, route)
+			SetCanaryAvailableRotationPortsMetric(len(service.Spec.Ports))
+			route, err = r.rotateRouteEndpoint(ctx, service, route)
 			if err != nil {
 				log.Error(err, "failed to rotate canary route endpoint")
 				return
 			}
+			r.markRotated()
 			checkCount = 0
-			// Give the router time to reload by returning here.
+			// Give the router time to reload by returning here; any
+			// remaining settle window is skipped above.
+			r.beginRotationSettle(&settle, time.Now())
 			return
 		}
 
-		err = probeRouteEndpoint(route)
+		probeResult, err := r.probeRouteEndpointWithRetries(ctx, route, probeRetryBaseDelay)
+		rotatedSinceLastProbe := r.consumeJustRotated()
+		// Track consecutive wrong-port echoes so that a persistently
+		// wedged router is reported distinctly from a one-off echo
+		// during a settling reload.
+		if r.trackWrongPortEcho(probeResult.WrongPortEcho) {
+			log.Error(fmt.Errorf("canary requests are consistently reaching the wrong backend port"), "router may be wedged and serving stale configuration", "echoedPort", probeResult.EchoedPort, "expectedPort", probeResult.ExpectedPort, "consecutiveWrongPortEchoes", r.consecutiveWrongPortEchoes)
+		}
+		if r.config.EnableIntrospection {
+			r.recordProbeResult(route.Spec.Host, probeResult, err)
+		}
+		stats := r.recordProbeStats(err == nil)
+		recordProbeHealth(err)
+		SetCanaryRouteSuccessRatioMetric(route.Spec.Host, r.recordSuccessRatio(err == nil))
+		r.emitProbeEvent(route.Spec.Host, err)
+		r.invokeProbeResultHooks(route.Spec.Host, probeResult, err)
+		if r.config.EnableInsightsSummary {
+			if err := r.updateInsightsSummary(stats); err != nil {
+				log.Error(err, "error updating canary insights summary")
+			}
+		}
 		if err != nil {
-			log.Error(err, "error performing canary route check")
-			SetCanaryRouteReachableMetric(route.Spec.Host, false)
+			if r.config.RecordRedirectChain && len(probeResult.RedirectChain) != 0 {
+				log.Info("canary probe redirect chain", "chain", probeResult.RedirectChain)
+			}
+			// A wrong-port echo on the first probe after a rotation
+			// may be a reused keep-alive connection still pinned to
+			// the old backend rather than a wedged router;
+			// distinguish the two by comparing a forced-fresh
+			// connection against a reused one.
+			if probeResult.WrongPortEcho && rotatedSinceLastProbe {
+				if cause, cerr := r.checkPostRotationReuse(route); cerr != nil {
+					log.Error(cerr, "error classifying post-rotation wrong-port echo")
+				} else if cause == wrongPortCauseReuseLeak {
+					CanaryConnectionReuseLeaks.Inc()
+					log.Info("wrong-port echo after rotation was caused by a reused connection pinned to the old backend, not a wedged router")
+				}
+			}
+			// Probe failures during a canary image rollout are
+			// expected; do not flip reachability while the
+			// rollout settles.
+			if r.inImageRolloutGracePeriod(time.Now()) {
+				log.Info("suppressing canary check failure during canary image rollout", "error", err)
+				return
+			}
+			if len(probeResult.BackendAddr) != 0 {
+				log.Info("canary probe backend", "backend", probeResult.BackendAddr)
+			}
+			// Log the failure, suppressing repeated identical
+			// reasons within the failure log window so that a
+			// long-lived outage does not bury other operator logs.
+			// Metrics and events below still update on every cycle.
+			if r.shouldLogFailure(err.Error(), time.Now()) {
+				// Call out DNS failures distinctly; they usually
+				// indicate a cluster DNS or infrastructure issue
+				// rather than an ingress issue.
+				if isDNSResolutionError(err) {
+					log.Error(err, "canary route check failed to resolve the route host; this usually indicates a cluster DNS or infrastructure issue rather than an ingress issue")
+				} else {
+					log.Error(err, "error performing canary route check")
+				}
+			}
 			successiveFail += 1
-			// Mark the default ingress controller degraded after 5 successive canary check failures
-			if successiveFail >= canaryCheckFailureCount {
-				if err := r.setCanaryFailingStatusCondition(); err != nil {
-					log.Error(err, "error updating canary status condition")
+			r.recordCanaryCheckEvent(route, successiveFail, err)
+			// Only flip the reachability gauge once the configured
+			// number of consecutive failures is reached, so that a
+			// single router reload does not flap the metric.
+			reachable := !r.shouldMarkUnreachable(successiveFail)
+			// With a quorum configured, a failed route probe alone
+			// does not decide reachability; probe the rest of the
+			// set first.
+			if r.config.ProbeQuorumFraction > 0 && len(r.config.ExtraProbeTargets) != 0 {
+				reachable = r.probeSetReachable(false)
+			}
+			SetCanaryRouteReachableMetric(route.Spec.Host, reachable)
+			// Mark the default ingress controller degraded once the
+			// successive-failure threshold is exceeded.
+			if r.shouldMarkDegraded(successiveFail) {
+				if condErr := r.setCanaryFailingStatusCondition(ctx, err); condErr != nil {
+					log.Error(condErr, "error updating canary status condition")
 				}
 			}
 			return
 		}
 
-		SetCanaryRouteReachableMetric(route.Spec.Host, true)
-		if err := r.setCanaryPassingStatusCondition(); err != nil {
+		// A recovered canary logs its next failure immediately rather
+		// than waiting out the suppression window.
+		r.resetFailureLog()
+
+		// Even a successful probe counts as unhealthy when its
+		// measured latency exceeds the configured SLO.
+		if r.enforceLatencySLO(route.Spec.Host, probeResult.Latency) {
+			return
+		}
+
+		// Record when the canary last succeeded so that alerts can
+		// fire on the age of the last success rather than on the
+		// binary reachability gauge.
+		SetCanaryLastSuccessTimeMetric(route.Spec.Host, time.Now())
+		r.recordCanaryCheckEvent(route, successiveFail, nil)
+
+		// Validate that the router honors the route's balance
+		// algorithm annotation.
+		if r.config.BalanceProbe {
+			if err := r.probeBalanceAlgorithm(route); err != nil {
+				log.Error(err, "error performing canary balance algorithm check")
+			}
+		}
+
+		// Validate the router's slow-loris protection. The probe
+		// can block for up to the configured window, so run it off
+		// the polling goroutine.
+		if r.config.SlowHeaderProbe {
+			go func() {
+				if err := r.probeSlowHeaders(route); err != nil {
+					log.Error(err, "error performing canary slow header check")
+				}
+			}()
+		}
+
+		// Validate that the router does not attempt HTTP/2 server
+		// push.
+		if r.config.H2NoPushProbe {
+			if err := r.probeH2NoPush(route); err != nil {
+				log.Error(err, "error performing canary HTTP/2 push check")
+			}
+		}
+
+		// Validate that the router preserves the backend's caching
+		// headers and injects none of its own.
+		if r.config.CacheHeaderProbe {
+			if err := r.probeCacheHeaders(route); err != nil {
+				log.Error(err, "error performing canary cache header check")
+			}
+		}
+
+		// Validate that 100-continue requests complete via the
+		// timeout fallback.
+		if r.config.ExpectContinueProbe {
+			if err := r.probeExpectContinue(route); err != nil {
+				log.Error(err, "error performing canary 100-continue check")
+			}
+		}
+
+		// Validate that request methods round-trip through the
+		// router unchanged.
+		if r.config.MethodEchoProbe {
+			if err := r.probeMethodEcho(route); err != nil {
+				log.Error(err, "error performing canary method echo check")
+			}
+		}
+
+		// Validate that the router handles pipelined requests
+		// safely.
+		if r.config.PipeliningProbe {
+			if err := r.probePipelining(route); err != nil {
+				log.Error(err, "error performing canary pipelining check")
+			}
+		}
+
+		// Validate that the router closes idle keep-alive
+		// connections. The probe blocks for the idle window, so run
+		// it off the polling goroutine.
+		if r.config.IdleTimeoutProbe {
+			go func() {
+				if err := r.probeIdleTimeout(route); err != nil {
+					log.Error(err, "error performing canary idle timeout check")
+				}
+			}()
+		}
+
+		// Validate that the router rejects requests for unknown
+		// hosts rather than leaking a default backend response.
+		if r.config.NegativeHostProbe {
+			if err := r.probeUnknownHost(route); err != nil {
+				log.Error(err, "error performing canary negative host check")
+			}
+		}
+
+		if len(r.config.ExtraProbeTargets) != 0 && r.config.ProbeQuorumFraction > 0 {
+			// With a quorum configured, probe the full set
+			// synchronously so that the cycle's aggregate
+			// reachability reflects every result.
+			SetCanaryRouteReachableMetric(route.Spec.Host, r.probeSetReachable(true))
+		} else {
+			// Probe any extra targets, staggered across a fraction of the
+			// polling interval so the probes are not bursty.
+			if len(r.config.ExtraProbeTargets) != 0 {
+				delays := extraProbeDelays(len(r.config.ExtraProbeTargets), pollInterval, r.config.ExtraProbeJitterFraction)
+				dispatchExtraProbes(r.config.ExtraProbeTargets, delays, func(target ProbeTarget) {
+					if err := r.probeExtraTarget(target); err != nil {
+						log.Error(err, "error performing extra canary target check", "target", target.URL)
+					}
+				})
+			}
+
+			SetCanaryRouteReachableMetric(route.Spec.Host, true)
+		}
+		if err := r.setCanaryPassingStatusCondition(ctx); err != nil {
 			log.Error(err, "error updating canary status condition")
 		}
 		successiveFail = 0
@@ -293,23 +1324,302 @@ func (r *reconciler) startCanaryRoutePolling(stop <-chan struct{}) error {
 		if rotationEnabled {
 			checkCount++
 		}
-	}, canaryCheckFrequency, stop)
+	}, pollInterval, pollJitterFactor(r.config.JitterFactor))
 
 	return nil
 }
 
-func (r *reconciler) setCanaryFailingStatusCondition() error {
+// superviseCanaryRoutePolling runs the polling loop, recovering from
+// panics in individual cycles and restarting the loop if it ever exits
+// while the context is still active. Without supervision, an exited
+// polling goroutine would silently stop canary checks forever.
+func superviseCanaryRoutePolling(ctx context.Context, pollFn func(context.Context), pollInterval time.Duration, jitterFactor float64) {
+	for {
+		wait.JitterUntilWithContext(ctx, withPollPanicRecovery(pollFn), pollInterval, jitterFactor, true)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Error(fmt.Errorf("canary polling loop exited while its context was still active"), "restarting canary polling loop")
+	}
+}
+
+// withPollPanicRecovery wraps a polling cycle with panic recovery so
+// that a bug in a single probe cannot crash the operator or kill the
+// polling goroutine; the panicking cycle is logged and skipped.
+func withPollPanicRecovery(pollFn func(context.Context)) func(context.Context) {
+	return func(ctx context.Context) {
+		defer func() {
+			if p := recover(); p != nil {
+				log.Error(fmt.Errorf("panic: %v", p), "recovered from a panic in a canary polling cycle")
+			}
+		}()
+		pollFn(ctx)
+	}
+}
+
+// consumeProbeTrigger returns true if the given ingress controller
+// carries an on-demand probe annotation whose value has not been
+// handled yet, recording the value so that each annotation change
+// triggers exactly one probe.
+func (r *reconciler) consumeProbeTrigger(ic *operatorv1.IngressController) bool {
+	val, ok := ic.Annotations[CanaryProbeNowAnnotation]
+	if !ok || val == r.lastProbeTrigger {
+		return false
+	}
+	r.lastProbeTrigger = val
+	return true
+}
+
+// runOnDemandProbe probes the canary route immediately and records the
+// outcome in the same reachability metrics that the polling loop
+// updates.
+func (r *reconciler) runOnDemandProbe(ctx context.Context, route *routev1.Route) {
+	log.Info("running on-demand canary probe", "host", route.Spec.Host)
+	_, err := r.probeRouteEndpointWithRetries(ctx, route, probeRetryBaseDelay)
+	if err != nil {
+		log.Error(err, "on-demand canary route check failed")
+	}
+	SetCanaryRouteReachableMetric(route.Spec.Host, err == nil)
+}
+
+// trackWrongPortEcho updates the consecutive wrong-port echo count
+// with the given probe outcome and returns true once the count reaches
+// the wedge threshold, meaning the router should be reported as
+// wedged. The router-wedged gauge is kept in sync with the returned
+// state.
+func (r *reconciler) trackWrongPortEcho(wrongPortEcho bool) bool {
+	if !wrongPortEcho {
+		r.consecutiveWrongPortEchoes = 0
+		SetCanaryRouterWedgedMetric(false)
+		return false
+	}
+	r.consecutiveWrongPortEchoes++
+	wedged := r.consecutiveWrongPortEchoes >= r.wedgeThreshold()
+	SetCanaryRouterWedgedMetric(wedged)
+	return wedged
+}
+
+// wedgeThreshold returns how many consecutive wrong-port echoes must
+// be observed before the router is reported as wedged, falling back to
+// the default when the configured threshold is unset.
+func (r *reconciler) wedgeThreshold() int {
+	if r.config.WedgeThreshold > 0 {
+		return r.config.WedgeThreshold
+	}
+	return defaultWedgeThreshold
+}
+
+// probeSecondaryCanaryRoutes probes every labeled canary route other
+// than the primary route and records a per-route reachability metric.
+// Secondary routes do not drive rotation or the ingress controller's
+// status conditions; they only surface per-shard reachability.
+func (r *reconciler) probeSecondaryCanaryRoutes(ctx context.Context, routes []routev1.Route, primaryName string) {
+	for i := range routes {
+		route := &routes[i]
+		if route.Name == primaryName {
+			continue
+		}
+		if !checkRouteAdmittedByAny(route) {
+			log.Info("skipping canary check for unadmitted canary route", "namespace", route.Namespace, "name", route.Name)
+			continue
+		}
+		_, err := r.probeRouteEndpointWithRetries(ctx, route, probeRetryBaseDelay)
+		if err != nil {
+			log.Error(err, "error performing canary route check", "namespace", route.Namespace, "name", route.Name)
+		}
+		SetCanaryRouteReachableMetric(route.Spec.Host, err == nil)
+	}
+}
+
+// rotationCycleCount returns how many polling cycles at the given
+// interval approximate the canary rotation cadence, so that rotation
+// frequency does not scale with the poll interval.
+func rotationCycleCount(interval time.Duration) int {
+	if interval <= 0 {
+		interval = canaryCheckFrequency
+	}
+	cycles := int(canaryRotationCadence / interval)
+	if cycles < 1 {
+		cycles = 1
+	}
+	return cycles
+}
+
+// apiReadContext derives a context with a bounded timeout from the
+// given context, so that individual API reads fail fast rather than
+// blocking the polling loop.
+func apiReadContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, apiReadTimeout)
+}
+
+// rotationThreshold returns how many successful canary checks must be
+// observed between route rotations, preferring the configured count
+// and otherwise deriving it from the poll interval so that the
+// rotation cadence stays roughly constant.
+func (r *reconciler) rotationThreshold(pollInterval time.Duration) int {
+	if r.config.RotationEveryNChecks > 0 {
+		return r.config.RotationEveryNChecks
+	}
+	return rotationCycleCount(pollInterval)
+}
+
+// latencyExceedsSLO returns true if the given probe latency exceeds
+// the configured maximum. Always false when no maximum is configured.
+func (r *reconciler) latencyExceedsSLO(latency time.Duration) bool {
+	return r.config.MaxLatency > 0 && latency > r.config.MaxLatency
+}
+
+// enforceLatencySLO records a latency SLO violation for the given host
+// and marks the route unreachable when the given latency exceeds the
+// configured maximum, returning true if the probe should be treated as
+// failed.
+func (r *reconciler) enforceLatencySLO(host string, latency time.Duration) bool {
+	if !r.latencyExceedsSLO(latency) {
+		return false
+	}
+	log.Error(fmt.Errorf("canary check latency %v exceeds the configured maximum %v", latency, r.config.MaxLatency), "canary route latency SLO exceeded", "host", host)
+	CanaryLatencySLOViolations.Inc()
+	SetCanaryRouteReachableMetric(host, false)
+	return true
+}
+
+// rotationSettle tracks the post-rotation quiet period during which
+// probes are suppressed while the router reloads: either until a
+// configured wall-clock delay elapses, or for a configured number of
+// polling cycles.
+type rotationSettle struct {
+	remaining int
+	until     time.Time
+}
+
+// beginRotationSettle starts the post-rotation settle window. The
+// rotation cycle itself counts as the first settle cycle in the
+// cycle-based mode.
+func (r *reconciler) beginRotationSettle(s *rotationSettle, now time.Time) {
+	if r.config.RotationSettleDelay > 0 {
+		s.until = now.Add(r.config.RotationSettleDelay)
+		return
+	}
+	s.remaining = rotationSettleCycles(r.config.RotationSettleCycles) - 1
+}
+
+// inRotationSettle reports whether a polling cycle at the given time
+// falls within the post-rotation settle window and should skip its
+// probe, consuming a settle cycle in the cycle-based mode.
+func (r *reconciler) inRotationSettle(s *rotationSettle, now time.Time) bool {
+	if r.config.RotationSettleDelay > 0 {
+		return now.Before(s.until)
+	}
+	if s.remaining > 0 {
+		s.remaining--
+		return true
+	}
+	return false
+}
+
+// rotationSettleCycles returns how many polling cycles are skipped
+// after a route rotation, falling back to a single cycle when the
+// configured count is unset.
+func rotationSettleCycles(configured int) int {
+	if configured > 0 {
+		return configured
+	}
+	return 1
+}
+
+// pollJitterFactor returns the polling jitter factor to use, falling
+// back to the default when the configured factor is unset.
+func pollJitterFactor(configured float64) float64 {
+	if configured > 0 {
+		return configured
+	}
+	return defaultPollJitterFactor
+}
+
+// inStartupGrace returns true if the given polling cycle is still
+// within the startup grace window during which a missing canary route
+// is expected rather than an error.
+func inStartupGrace(cycle, graceCycles int) bool {
+	if graceCycles == 0 {
+		graceCycles = defaultStartupGraceCycles
+	}
+	return cycle <= graceCycles
+}
+
+// shouldMarkUnreachable returns true if the given number of
+// consecutive canary check failures warrants flipping the route's
+// reachability gauge to unreachable. The threshold defaults to 1 when
+// Config.FailureThreshold is unset, preserving the flip-on-first-
+// failure behavior.
+func (r *reconciler) shouldMarkUnreachable(successiveFail int) bool {
+	threshold := r.config.FailureThreshold
+	if threshold == 0 {
+		threshold = 1
+	}
+	return successiveFail >= threshold
+}
+
+// shouldMarkDegraded returns true if the given number of successive
+// canary check failures warrants marking the default ingress
+// controller degraded. A single failed cycle should not flip the
+// degraded-affecting condition, so the threshold defaults to
+// canaryCheckFailureCount when Config.DegradedThreshold is unset.
+func (r *reconciler) shouldMarkDegraded(successiveFail int) bool {
+	threshold := r.config.DegradedThreshold
+	if threshold == 0 {
+		threshold = canaryCheckFailureCount
+	}
+	return successiveFail >= threshold
+}
+
+// defaultFailureLogWindow is how long an unchanged canary check
+// failure reason is suppressed after being logged once, when
+// Config.FailureLogWindow is unset.
+const defaultFailureLogWindow = 10 * time.Minute
+
+// shouldLogFailure returns true if the given canary check failure
+// reason should be logged now. An unchanged reason is logged at most
+// once per failure log window; a changed reason is logged immediately.
+func (r *reconciler) shouldLogFailure(reason string, now time.Time) bool {
+	window := r.config.FailureLogWindow
+	if window == 0 {
+		window = defaultFailureLogWindow
+	}
+	if reason == r.lastFailureReason && now.Sub(r.lastFailureLogTime) < window {
+		return false
+	}
+	r.lastFailureReason = reason
+	r.lastFailureLogTime = now
+	return true
+}
+
+// resetFailureLog clears the failure log suppression state so that the
+// next failure after a recovery is logged immediately.
+func (r *reconciler) resetFailureLog() {
+	r.lastFailureReason = ""
+	r.lastFailureLogTime = time.Time{}
+}
+
+// setCanaryFailingStatusCondition marks the canary check condition
+// False. DNS resolution failures get a dedicated reason since they
+// usually indicate a cluster DNS or infrastructure issue rather than
+// an ingress issue.
+func (r *reconciler) setCanaryFailingStatusCondition(ctx context.Context, checkErr error) error {
 	cond := operatorv1.OperatorCondition{
 		Type:    ingresscontroller.IngressControllerCanaryCheckSuccessConditionType,
 		Status:  operatorv1.ConditionFalse,
 		Reason:  "CanaryChecksRepetitiveFailures",
 		Message: "Canary route checks for the default ingress controller are failing",
 	}
+	if isDNSResolutionError(checkErr) {
+		cond.Reason = "CanaryChecksDNSFailures"
+		cond.Message = "Canary route checks for the default ingress controller are failing to resolve the route host, which usually indicates a cluster DNS issue"
+	}
 
-	return r.setCanaryStatusCondition(cond)
+	return r.setCanaryStatusCondition(ctx, cond)
 }
 
-func (r *reconciler) setCanaryPassingStatusCondition() error {
+func (r *reconciler) setCanaryPassingStatusCondition(ctx context.Context) error {
 	cond := operatorv1.OperatorCondition{
 		Type:    ingresscontroller.IngressControllerCanaryCheckSuccessConditionType,
 		Status:  operatorv1.ConditionTrue,
@@ -317,20 +1627,20 @@ func (r *reconciler) setCanaryPassingStatusCondition() error {
 		Message: "Canary route checks for the default ingress controller are successful",
 	}
 
-	return r.setCanaryStatusCondition(cond)
+	return r.setCanaryStatusCondition(ctx, cond)
 }
 
 // setCanaryStatusCondition applies the given condition to the default ingress controller.
 // The assumption here is that cond is a condition that does not overlap with any of the status
 // conditions set by the ingress controller in pkg/operator/controller/ingress/status.go.
-func (r *reconciler) setCanaryStatusCondition(cond operatorv1.OperatorCondition) error {
+func (r *reconciler) setCanaryStatusCondition(ctx context.Context, cond operatorv1.OperatorCondition) error {
 	ic := &operatorv1.IngressController{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      manifests.DefaultIngressControllerName,
 			Namespace: r.config.Namespace,
 		},
 	}
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}, ic); err != nil {
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}, ic); err != nil {
 		return fmt.Errorf("failed to get ingress controller %s: %v", ic.Name, err)
 	}
 
@@ -338,7 +1648,7 @@ func (r *reconciler) setCanaryStatusCondition(cond operatorv1.OperatorCondition)
 	updated.Status.Conditions = ingresscontroller.MergeConditions(updated.Status.Conditions, cond)
 
 	if !ingresscontroller.IngressStatusesEqual(updated.Status, ic.Status) {
-		if err := r.client.Status().Update(context.TODO(), updated); err != nil {
+		if err := r.client.Status().Update(ctx, updated); err != nil {
 			return fmt.Errorf("failed to update ingresscontroller %s status: %v", ic.Name, err)
 		}
 	}
@@ -349,21 +1659,79 @@ func (r *reconciler) setCanaryStatusCondition(cond operatorv1.OperatorCondition)
 // Switch the current RoutePort that the route points to.
 // Use this function to periodically update the canary route endpoint
 // to verify if the router has wedged.
-func (r *reconciler) rotateRouteEndpoint(service *corev1.Service, current *routev1.Route) (*routev1.Route, error) {
-	updated, err := cycleServicePort(service, current)
+func (r *reconciler) rotateRouteEndpoint(ctx context.Context, service *corev1.Service, current *routev1.Route) (*routev1.Route, error) {
+	// Only allow one rotation at a time so that interleaved rotations
+	// cannot leave the route on an unexpected port.
+	r.rotationMu.Lock()
+	defer r.rotationMu.Unlock()
+
+	// Re-read the route so that rotation starts from the authoritative
+	// current port, even if another rotation completed while this one
+	// was waiting on the lock.
+	if haveRoute, latest, err := r.currentCanaryRoute(ctx); err != nil {
+		CanaryRoutePortRotationFailures.Inc()
+		return nil, fmt.Errorf("failed to get current canary route before rotation: %v", err)
+	} else if haveRoute {
+		current = latest
+	}
+
+	// A route without a port cannot be rotated; the next reconcile
+	// repairs the port from the canary service.
+	if current.Spec.Port == nil {
+		CanaryRouteMisconfigured.Inc()
+		return nil, fmt.Errorf("canary route %s/%s has no Spec.Port, cannot rotate route port", current.Namespace, current.Name)
+	}
+
+	// Restrict rotation to the configured port subset, if any.
+	updated, err := cycleServicePort(r.restrictToRotationPorts(service), current)
 	if err != nil {
+		CanaryRoutePortRotationFailures.Inc()
 		return nil, fmt.Errorf("failed to rotate route port: %v", err)
 	}
 
-	if changed, err := r.updateCanaryRoute(current, updated); err != nil {
+	if changed, err := r.updateCanaryRoute(ctx, current, updated); err != nil {
+		CanaryRoutePortRotationFailures.Inc()
 		return current, err
 	} else if !changed {
+		CanaryRoutePortRotationFailures.Inc()
 		return current, fmt.Errorf("expected canary route to be updated: No relevant changes detected")
 	}
 
+	CanaryRoutePortRotations.Inc()
 	return updated, nil
 }
 
+// restrictToRotationPorts returns the given service restricted to the
+// ports named in the configured rotation port subset, so that rotation
+// only toggles between ports the operator has singled out. The service
+// is returned unchanged when no subset is configured or when the
+// intersection with the service's actual ports is empty.
+func (r *reconciler) restrictToRotationPorts(service *corev1.Service) *corev1.Service {
+	if len(r.config.RotationPorts) == 0 {
+		return service
+	}
+
+	restricted := []corev1.ServicePort{}
+	for _, port := range service.Spec.Ports {
+		for _, rotationPort := range r.config.RotationPorts {
+			if cmp.Equal(port.TargetPort, rotationPort) {
+				restricted = append(restricted, port)
+				break
+			}
+		}
+	}
+	// None of the configured ports exist on the service; fall back to
+	// the full port list rather than making rotation impossible.
+	if len(restricted) == 0 {
+		log.Info("none of the configured rotation ports exist on the canary service, rotating among all service ports", "rotationPorts", r.config.RotationPorts)
+		return service
+	}
+
+	updated := service.DeepCopy()
+	updated.Spec.Ports = restricted
+	return updated
+}
+
 // cycleServicePort returns a route resource with Spec.Port set to the
 // next available port in service.Spec.Ports that is not the current route.Spec.Port.
 func cycleServicePort(service *corev1.Service, route *routev1.Route) (*routev1.Route, error) {
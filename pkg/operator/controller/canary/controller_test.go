@@ -1,14 +1,38 @@
 package canary
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
 	routev1 "github.com/openshift/api/route/v1"
 
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+	ingresscontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller/ingress"
+
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 func TestCycleServicePort(t *testing.T) {
@@ -145,3 +169,1099 @@ func TestCycleServicePort(t *testing.T) {
 		}
 	}
 }
+
+func TestInStartupGrace(t *testing.T) {
+	testCases := []struct {
+		description string
+		cycle       int
+		graceCycles int
+		expect      bool
+	}{
+		{
+			description: "first cycle with the default grace",
+			cycle:       1,
+			graceCycles: 0,
+			expect:      true,
+		},
+		{
+			description: "cycle past the default grace",
+			cycle:       defaultStartupGraceCycles + 1,
+			graceCycles: 0,
+			expect:      false,
+		},
+		{
+			description: "cycle within a configured grace",
+			cycle:       9,
+			graceCycles: 10,
+			expect:      true,
+		},
+		{
+			description: "cycle past a configured grace",
+			cycle:       11,
+			graceCycles: 10,
+			expect:      false,
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := inStartupGrace(tc.cycle, tc.graceCycles); got != tc.expect {
+			t.Errorf("%s: expected inStartupGrace to be %t, but got %t", tc.description, tc.expect, got)
+		}
+	}
+}
+
+func TestResolveCanaryImage(t *testing.T) {
+	testCases := []struct {
+		description   string
+		configured    string
+		envImage      string
+		expectedImage string
+		expectError   bool
+	}{
+		{
+			description:   "explicitly configured image takes precedence",
+			configured:    "registry.example.com/canary:explicit",
+			envImage:      "registry.example.com/canary:release",
+			expectedImage: "registry.example.com/canary:explicit",
+		},
+		{
+			description:   "image is resolved from the release payload env when unset",
+			configured:    "",
+			envImage:      "registry.example.com/canary:release",
+			expectedImage: "registry.example.com/canary:release",
+		},
+		{
+			description: "error when no image is available",
+			configured:  "",
+			envImage:    "",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		if len(tc.envImage) != 0 {
+			os.Setenv(canaryImageEnvName, tc.envImage)
+		} else {
+			os.Unsetenv(canaryImageEnvName)
+		}
+
+		image, err := resolveCanaryImage(tc.configured)
+		if tc.expectError {
+			if err == nil {
+				t.Errorf("%s: expected resolveCanaryImage to return an error, but it did not", tc.description)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: resolveCanaryImage returned an unexpected error: %v", tc.description, err)
+		} else if image != tc.expectedImage {
+			t.Errorf("%s: expected image %q, but got %q", tc.description, tc.expectedImage, image)
+		}
+	}
+	os.Unsetenv(canaryImageEnvName)
+}
+
+func TestRotateRouteEndpointSerialized(t *testing.T) {
+	routeName := controller.CanaryRouteName()
+	tPort1 := intstr.FromString("8080")
+	tPort2 := intstr.FromString("8888")
+
+	route := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: routeName.Namespace,
+			Name:      routeName.Name,
+		},
+		Spec: routev1.RouteSpec{
+			Port: &routev1.RoutePort{
+				TargetPort: tPort1,
+			},
+		},
+	}
+	service := &corev1.Service{
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{TargetPort: tPort1},
+				{TargetPort: tPort2},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(operatorclient.GetScheme()).WithObjects(route).Build()
+	r := &reconciler{
+		client: fakeClient,
+	}
+
+	rotations := 10
+	var wg sync.WaitGroup
+	for i := 0; i < rotations; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := r.rotateRouteEndpoint(context.TODO(), service, route); err != nil {
+				t.Errorf("rotateRouteEndpoint returned an error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	final := &routev1.Route{}
+	if err := fakeClient.Get(context.TODO(), routeName, final); err != nil {
+		t.Fatalf("failed to get canary route: %v", err)
+	}
+
+	// An even number of serialized rotations on a two-port service
+	// must land back on the starting port.
+	if !cmp.Equal(final.Spec.Port.TargetPort, tPort1) {
+		t.Errorf("expected route to end on port %v after %d rotations, but got %v", tPort1, rotations, final.Spec.Port.TargetPort)
+	}
+}
+
+func TestRotateRouteEndpointRotationCounters(t *testing.T) {
+	routeName := controller.CanaryRouteName()
+	tPort1 := intstr.FromString("8080")
+	tPort2 := intstr.FromString("8888")
+
+	route := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: routeName.Namespace,
+			Name:      routeName.Name,
+		},
+		Spec: routev1.RouteSpec{
+			Port: &routev1.RoutePort{
+				TargetPort: tPort1,
+			},
+		},
+	}
+	service := &corev1.Service{
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{TargetPort: tPort1},
+				{TargetPort: tPort2},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(operatorclient.GetScheme()).WithObjects(route).Build()
+	r := &reconciler{
+		client: fakeClient,
+	}
+
+	rotations := testutil.ToFloat64(CanaryRoutePortRotations)
+	failures := testutil.ToFloat64(CanaryRoutePortRotationFailures)
+
+	if _, err := r.rotateRouteEndpoint(context.TODO(), service, route); err != nil {
+		t.Fatalf("rotateRouteEndpoint returned an error: %v", err)
+	}
+	if got := testutil.ToFloat64(CanaryRoutePortRotations); got != rotations+1 {
+		t.Errorf("expected rotation counter to be %v after a successful rotation, but got %v", rotations+1, got)
+	}
+	if got := testutil.ToFloat64(CanaryRoutePortRotationFailures); got != failures {
+		t.Errorf("expected rotation failure counter to stay at %v after a successful rotation, but got %v", failures, got)
+	}
+
+	// A single-port service cannot be rotated, so the rotation must
+	// fail and increment the failure counter.
+	singlePortService := &corev1.Service{
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{TargetPort: tPort1},
+			},
+		},
+	}
+	if _, err := r.rotateRouteEndpoint(context.TODO(), singlePortService, route); err == nil {
+		t.Fatal("expected rotateRouteEndpoint to return an error for a single-port service, but it did not")
+	}
+	if got := testutil.ToFloat64(CanaryRoutePortRotations); got != rotations+1 {
+		t.Errorf("expected rotation counter to stay at %v after a failed rotation, but got %v", rotations+1, got)
+	}
+	if got := testutil.ToFloat64(CanaryRoutePortRotationFailures); got != failures+1 {
+		t.Errorf("expected rotation failure counter to be %v after a failed rotation, but got %v", failures+1, got)
+	}
+}
+
+// TestRotateRouteEndpointNilPort verifies that rotating a route that
+// has no Spec.Port fails cleanly and registers the misconfiguration in
+// metrics instead of panicking.
+func TestRotateRouteEndpointNilPort(t *testing.T) {
+	routeName := controller.CanaryRouteName()
+	route := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: routeName.Namespace,
+			Name:      routeName.Name,
+		},
+	}
+	service := &corev1.Service{
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{TargetPort: intstr.FromString("8080")},
+				{TargetPort: intstr.FromString("8888")},
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(operatorclient.GetScheme()).WithObjects(route).Build()
+	r := &reconciler{
+		client: fakeClient,
+	}
+
+	before := testutil.ToFloat64(CanaryRouteMisconfigured)
+	_, err := r.rotateRouteEndpoint(context.TODO(), service, route)
+	if err == nil {
+		t.Fatal("expected rotateRouteEndpoint to return an error for a route without Spec.Port, but it did not")
+	}
+	if !strings.Contains(err.Error(), "has no Spec.Port") {
+		t.Fatalf("expected the error to mention the missing Spec.Port, but got %v", err)
+	}
+	if after := testutil.ToFloat64(CanaryRouteMisconfigured); after != before+1 {
+		t.Errorf("expected the misconfigured route counter to increment from %v to %v, but got %v", before, before+1, after)
+	}
+}
+
+func TestAPIReadContext(t *testing.T) {
+	ctx, cancel := apiReadContext(context.Background())
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		t.Fatal("expected API read context to have a deadline, but it has none")
+	}
+	if remaining := time.Until(deadline); remaining > apiReadTimeout {
+		t.Errorf("expected API read deadline within %v, but got %v", apiReadTimeout, remaining)
+	}
+
+	// A parent deadline earlier than the read timeout is preserved.
+	parent, parentCancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer parentCancel()
+	ctx, cancel = apiReadContext(parent)
+	defer cancel()
+	deadline, _ = ctx.Deadline()
+	if remaining := time.Until(deadline); remaining > time.Millisecond {
+		t.Errorf("expected the parent's earlier deadline to be preserved, but got %v", remaining)
+	}
+}
+
+func TestRotationThreshold(t *testing.T) {
+	testCases := []struct {
+		description          string
+		rotationEveryNChecks int
+		pollInterval         time.Duration
+		expectedThreshold    int
+	}{
+		{
+			description:          "threshold derived from the default poll interval",
+			rotationEveryNChecks: 0,
+			pollInterval:         canaryCheckFrequency,
+			expectedThreshold:    canaryCheckCycleCount,
+		},
+		{
+			description:          "threshold derived from a faster poll interval",
+			rotationEveryNChecks: 0,
+			pollInterval:         canaryCheckFrequency / 2,
+			expectedThreshold:    2 * canaryCheckCycleCount,
+		},
+		{
+			description:          "configured check count overrides the derived threshold",
+			rotationEveryNChecks: 12,
+			pollInterval:         canaryCheckFrequency,
+			expectedThreshold:    12,
+		},
+	}
+
+	for _, tc := range testCases {
+		r := &reconciler{
+			config: Config{
+				RotationEveryNChecks: tc.rotationEveryNChecks,
+			},
+		}
+		if threshold := r.rotationThreshold(tc.pollInterval); threshold != tc.expectedThreshold {
+			t.Errorf("%s: expected rotation threshold %d, but got %d", tc.description, tc.expectedThreshold, threshold)
+		}
+	}
+}
+
+func TestRotationSettleCycles(t *testing.T) {
+	testCases := []struct {
+		description    string
+		configured     int
+		expectedCycles int
+	}{
+		{
+			description:    "unset count falls back to a single cycle",
+			configured:     0,
+			expectedCycles: 1,
+		},
+		{
+			description:    "configured count is used",
+			configured:     3,
+			expectedCycles: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		if cycles := rotationSettleCycles(tc.configured); cycles != tc.expectedCycles {
+			t.Errorf("%s: expected %d settle cycles, but got %d", tc.description, tc.expectedCycles, cycles)
+		}
+	}
+}
+
+func TestPollJitterFactor(t *testing.T) {
+	testCases := []struct {
+		description    string
+		configured     float64
+		expectedFactor float64
+	}{
+		{
+			description:    "unset factor falls back to the default",
+			configured:     0,
+			expectedFactor: defaultPollJitterFactor,
+		},
+		{
+			description:    "configured factor is used",
+			configured:     0.25,
+			expectedFactor: 0.25,
+		},
+	}
+
+	for _, tc := range testCases {
+		if factor := pollJitterFactor(tc.configured); factor != tc.expectedFactor {
+			t.Errorf("%s: expected jitter factor %v, but got %v", tc.description, tc.expectedFactor, factor)
+		}
+	}
+}
+
+func TestRecordCanaryCheckEvent(t *testing.T) {
+	testCases := []struct {
+		description    string
+		successiveFail int
+		checkError     error
+		expectedEvent  string
+	}{
+		{
+			description:    "first failure emits a failing event",
+			successiveFail: 1,
+			checkError:     fmt.Errorf("probe failed"),
+			expectedEvent:  "CanaryCheckFailing",
+		},
+		{
+			description:    "repeated failure emits no event",
+			successiveFail: 2,
+			checkError:     fmt.Errorf("probe failed"),
+			expectedEvent:  "",
+		},
+		{
+			description:    "success after failures emits a recovery event",
+			successiveFail: 2,
+			checkError:     nil,
+			expectedEvent:  "CanaryCheckRecovered",
+		},
+		{
+			description:    "success after success emits no event",
+			successiveFail: 0,
+			checkError:     nil,
+			expectedEvent:  "",
+		},
+	}
+
+	for _, tc := range testCases {
+		recorder := record.NewFakeRecorder(10)
+		r := &reconciler{
+			recorder: recorder,
+		}
+		route := &routev1.Route{
+			Spec: routev1.RouteSpec{
+				Host: "canary.example.com",
+			},
+		}
+
+		r.recordCanaryCheckEvent(route, tc.successiveFail, tc.checkError)
+
+		select {
+		case event := <-recorder.Events:
+			if len(tc.expectedEvent) == 0 {
+				t.Errorf("%s: expected no event, but got %q", tc.description, event)
+			} else if !strings.Contains(event, tc.expectedEvent) {
+				t.Errorf("%s: expected event to contain %q, but got %q", tc.description, tc.expectedEvent, event)
+			} else if !strings.Contains(event, route.Spec.Host) {
+				t.Errorf("%s: expected event to contain host %q, but got %q", tc.description, route.Spec.Host, event)
+			}
+		default:
+			if len(tc.expectedEvent) != 0 {
+				t.Errorf("%s: expected an event containing %q, but none was recorded", tc.description, tc.expectedEvent)
+			}
+		}
+	}
+}
+
+func TestDegradedThreshold(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      manifests.DefaultIngressControllerName,
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(operatorclient.GetScheme()).WithObjects(ic).Build()
+	r := &reconciler{
+		client: fakeClient,
+		config: Config{
+			Namespace:         "openshift-ingress-operator",
+			DegradedThreshold: 3,
+		},
+	}
+
+	canaryCondition := func() *operatorv1.OperatorCondition {
+		current := &operatorv1.IngressController{}
+		if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: ic.Namespace, Name: ic.Name}, current); err != nil {
+			t.Fatalf("failed to get ingress controller: %v", err)
+		}
+		for i := range current.Status.Conditions {
+			if current.Status.Conditions[i].Type == ingresscontroller.IngressControllerCanaryCheckSuccessConditionType {
+				return &current.Status.Conditions[i]
+			}
+		}
+		return nil
+	}
+
+	// Failures below the threshold must not flip the condition.
+	for successiveFail := 1; successiveFail < 3; successiveFail++ {
+		if r.shouldMarkDegraded(successiveFail) {
+			if err := r.setCanaryFailingStatusCondition(context.TODO(), fmt.Errorf("probe failed")); err != nil {
+				t.Fatalf("failed to set canary status condition: %v", err)
+			}
+		}
+		if cond := canaryCondition(); cond != nil && cond.Status == operatorv1.ConditionFalse {
+			t.Fatalf("expected canary condition to not be False after %d failures, but got %v", successiveFail, cond.Status)
+		}
+	}
+
+	// The threshold failure flips the condition to False.
+	if !r.shouldMarkDegraded(3) {
+		t.Fatal("expected shouldMarkDegraded to be true at the threshold")
+	}
+	if err := r.setCanaryFailingStatusCondition(context.TODO(), fmt.Errorf("probe failed")); err != nil {
+		t.Fatalf("failed to set canary status condition: %v", err)
+	}
+	if cond := canaryCondition(); cond == nil || cond.Status != operatorv1.ConditionFalse {
+		t.Fatalf("expected canary condition to be False at the threshold, but got %v", cond)
+	}
+
+	// The condition recovers on the first successful check.
+	if err := r.setCanaryPassingStatusCondition(context.TODO()); err != nil {
+		t.Fatalf("failed to set canary status condition: %v", err)
+	}
+	if cond := canaryCondition(); cond == nil || cond.Status != operatorv1.ConditionTrue {
+		t.Fatalf("expected canary condition to be True after a successful check, but got %v", cond)
+	}
+
+	// The default threshold applies when none is configured.
+	r.config.DegradedThreshold = 0
+	if r.shouldMarkDegraded(canaryCheckFailureCount - 1) {
+		t.Error("expected shouldMarkDegraded to be false below the default threshold")
+	}
+	if !r.shouldMarkDegraded(canaryCheckFailureCount) {
+		t.Error("expected shouldMarkDegraded to be true at the default threshold")
+	}
+}
+
+func TestFailureThreshold(t *testing.T) {
+	host := "canary-openshift-ingress-canary.apps.example.com"
+	r := &reconciler{
+		config: Config{
+			FailureThreshold: 3,
+		},
+	}
+
+	reachable := func() float64 {
+		return testutil.ToFloat64(CanaryRouteReachable.WithLabelValues(host))
+	}
+
+	// Failures below the threshold keep the route reachable.
+	SetCanaryRouteReachableMetric(host, true)
+	for successiveFail := 1; successiveFail < 3; successiveFail++ {
+		SetCanaryRouteReachableMetric(host, !r.shouldMarkUnreachable(successiveFail))
+		if reachable() != 1 {
+			t.Fatalf("expected route to remain reachable after %d failures", successiveFail)
+		}
+	}
+
+	// The threshold failure flips the route to unreachable.
+	SetCanaryRouteReachableMetric(host, !r.shouldMarkUnreachable(3))
+	if reachable() != 0 {
+		t.Fatal("expected route to be unreachable at the threshold")
+	}
+
+	// The default threshold flips on the first failure.
+	r.config.FailureThreshold = 0
+	if !r.shouldMarkUnreachable(1) {
+		t.Error("expected shouldMarkUnreachable to be true on the first failure with the default threshold")
+	}
+}
+
+func TestRotationCycleCount(t *testing.T) {
+	testCases := []struct {
+		description string
+		interval    time.Duration
+		expected    int
+	}{
+		{
+			description: "default interval preserves the default threshold",
+			interval:    canaryCheckFrequency,
+			expected:    canaryCheckCycleCount,
+		},
+		{
+			description: "halving the interval doubles the threshold",
+			interval:    30 * time.Second,
+			expected:    10,
+		},
+		{
+			description: "a long interval rotates every cycle",
+			interval:    10 * time.Minute,
+			expected:    1,
+		},
+		{
+			description: "zero interval falls back to the default",
+			interval:    0,
+			expected:    canaryCheckCycleCount,
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := rotationCycleCount(tc.interval); got != tc.expected {
+			t.Errorf("%s: expected rotation cycle count %d, but got %d", tc.description, tc.expected, got)
+		}
+	}
+}
+
+// TestCycleServicePortWalksAllPorts verifies that repeated rotations
+// deterministically visit every service port exactly once per cycle
+// before wrapping back around to the starting port.
+func TestCycleServicePortWalksAllPorts(t *testing.T) {
+	ports := []intstr.IntOrString{
+		intstr.FromString("8080"),
+		intstr.FromString("8888"),
+		intstr.FromString("9090"),
+	}
+	service := &corev1.Service{
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{TargetPort: ports[0]},
+				{TargetPort: ports[1]},
+				{TargetPort: ports[2]},
+			},
+		},
+	}
+	route := &routev1.Route{
+		Spec: routev1.RouteSpec{
+			Port: &routev1.RoutePort{
+				TargetPort: ports[0],
+			},
+		},
+	}
+
+	visits := map[string]int{}
+	for i := 0; i < len(ports); i++ {
+		updated, err := cycleServicePort(service, route)
+		if err != nil {
+			t.Fatalf("cycleServicePort returned an error on rotation %d: %v", i, err)
+		}
+		visits[updated.Spec.Port.TargetPort.String()]++
+		route = updated
+	}
+
+	for _, port := range ports {
+		if visits[port.String()] != 1 {
+			t.Errorf("expected port %s to be visited exactly once per cycle, but got %d visits", port.String(), visits[port.String()])
+		}
+	}
+
+	// A full cycle lands back on the starting port.
+	if !cmp.Equal(route.Spec.Port.TargetPort, ports[0]) {
+		t.Errorf("expected a full rotation cycle to return to port %s, but got %s", ports[0].String(), route.Spec.Port.TargetPort.String())
+	}
+}
+
+// TestSuperviseCanaryRoutePolling verifies that a panic in a polling
+// cycle does not kill the polling loop and that the loop stops once
+// its context is canceled.
+func TestSuperviseCanaryRoutePolling(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cycles := 0
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		superviseCanaryRoutePolling(ctx, func(_ context.Context) {
+			cycles++
+			if cycles == 2 {
+				panic("boom")
+			}
+			if cycles >= 4 {
+				cancel()
+			}
+		}, time.Millisecond, defaultPollJitterFactor)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected the polling loop to keep running after a panic and stop on context cancellation, but it did not finish in time")
+	}
+
+	if cycles < 4 {
+		t.Errorf("expected at least 4 polling cycles despite a panic, but got %d", cycles)
+	}
+}
+
+// TestTrackWrongPortEcho verifies that the router is only reported as
+// wedged once the configured number of consecutive wrong-port echoes
+// is reached, and that any correct-port probe resets the count.
+func TestTrackWrongPortEcho(t *testing.T) {
+	r := &reconciler{}
+
+	// The default threshold tolerates two one-off echoes.
+	for i := 0; i < defaultWedgeThreshold-1; i++ {
+		if r.trackWrongPortEcho(true) {
+			t.Errorf("expected %d consecutive wrong-port echoes to not report a wedged router", i+1)
+		}
+	}
+	if !r.trackWrongPortEcho(true) {
+		t.Errorf("expected %d consecutive wrong-port echoes to report a wedged router", defaultWedgeThreshold)
+	}
+	if value := testutil.ToFloat64(CanaryRouterWedged); value != 1 {
+		t.Errorf("expected the router-wedged gauge to be 1, but got %v", value)
+	}
+
+	// A correct-port probe resets the count and clears the gauge.
+	if r.trackWrongPortEcho(false) {
+		t.Error("expected a correct-port probe to not report a wedged router")
+	}
+	if r.consecutiveWrongPortEchoes != 0 {
+		t.Errorf("expected the consecutive wrong-port echo count to reset, but got %d", r.consecutiveWrongPortEchoes)
+	}
+	if value := testutil.ToFloat64(CanaryRouterWedged); value != 0 {
+		t.Errorf("expected the router-wedged gauge to be 0, but got %v", value)
+	}
+
+	// A configured threshold takes precedence over the default.
+	r = &reconciler{
+		config: Config{
+			WedgeThreshold: 1,
+		},
+	}
+	if !r.trackWrongPortEcho(true) {
+		t.Error("expected a single wrong-port echo to report a wedged router with a threshold of 1")
+	}
+	SetCanaryRouterWedgedMetric(false)
+}
+
+// TestConsumeProbeTrigger verifies that each distinct value of the
+// on-demand probe annotation triggers exactly one probe.
+func TestConsumeProbeTrigger(t *testing.T) {
+	r := &reconciler{}
+	ic := &operatorv1.IngressController{}
+
+	if r.consumeProbeTrigger(ic) {
+		t.Error("expected no probe trigger without the annotation")
+	}
+
+	ic.Annotations = map[string]string{
+		CanaryProbeNowAnnotation: "1",
+	}
+	if !r.consumeProbeTrigger(ic) {
+		t.Error("expected a new annotation value to trigger a probe")
+	}
+	if r.consumeProbeTrigger(ic) {
+		t.Error("expected an already-handled annotation value to not trigger a probe")
+	}
+
+	ic.Annotations[CanaryProbeNowAnnotation] = "2"
+	if !r.consumeProbeTrigger(ic) {
+		t.Error("expected a changed annotation value to trigger a probe")
+	}
+}
+
+// TestRunOnDemandProbe verifies that an on-demand probe updates the
+// route reachability metric.
+func TestRunOnDemandProbe(t *testing.T) {
+	healthyServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set(echoServerPortAckHeader, "8080")
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	defer healthyServer.Close()
+
+	failingServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failingServer.Close()
+
+	r := &reconciler{}
+
+	route := routeForServer(healthyServer, "8080")
+	r.runOnDemandProbe(context.TODO(), route)
+	if value := testutil.ToFloat64(CanaryRouteReachable.WithLabelValues(route.Spec.Host)); value != 1 {
+		t.Errorf("expected an on-demand probe of a healthy endpoint to set the reachability gauge to 1, but got %v", value)
+	}
+
+	route = routeForServer(failingServer, "8080")
+	r.runOnDemandProbe(context.TODO(), route)
+	if value := testutil.ToFloat64(CanaryRouteReachable.WithLabelValues(route.Spec.Host)); value != 0 {
+		t.Errorf("expected an on-demand probe of a failing endpoint to set the reachability gauge to 0, but got %v", value)
+	}
+}
+
+// TestRotationSettle verifies that probes are suppressed after a route
+// rotation until the configured settle delay elapses, or for the
+// configured number of polling cycles when no delay is set.
+func TestRotationSettle(t *testing.T) {
+	start := time.Now()
+
+	// Delay-based settle: probes are suppressed until the configured
+	// wall-clock delay elapses, regardless of how many cycles occur.
+	r := &reconciler{
+		config: Config{
+			RotationSettleDelay: 5 * time.Minute,
+		},
+	}
+	settle := rotationSettle{}
+	r.beginRotationSettle(&settle, start)
+	if !r.inRotationSettle(&settle, start.Add(time.Minute)) {
+		t.Errorf("expected probes to be suppressed 1 minute into a 5 minute settle delay")
+	}
+	if !r.inRotationSettle(&settle, start.Add(4*time.Minute)) {
+		t.Errorf("expected probes to be suppressed 4 minutes into a 5 minute settle delay")
+	}
+	if r.inRotationSettle(&settle, start.Add(6*time.Minute)) {
+		t.Errorf("expected probes to resume after the 5 minute settle delay elapsed")
+	}
+
+	// Cycle-based settle: the rotation cycle itself counts as the
+	// first settle cycle, so two further cycles are skipped.
+	r = &reconciler{
+		config: Config{
+			RotationSettleCycles: 3,
+		},
+	}
+	settle = rotationSettle{}
+	r.beginRotationSettle(&settle, start)
+	for i := 0; i < 2; i++ {
+		if !r.inRotationSettle(&settle, start) {
+			t.Errorf("expected settle cycle %d of 2 to be suppressed", i+1)
+		}
+	}
+	if r.inRotationSettle(&settle, start) {
+		t.Errorf("expected probes to resume after the configured settle cycles were consumed")
+	}
+
+	// Default behavior: only the rotation cycle itself is skipped.
+	r = &reconciler{}
+	settle = rotationSettle{}
+	r.beginRotationSettle(&settle, start)
+	if r.inRotationSettle(&settle, start) {
+		t.Errorf("expected probes to resume on the first cycle after a rotation by default")
+	}
+}
+
+// TestEnforceLatencySLO verifies that a probe under the configured
+// latency SLO passes untouched while one over it increments the
+// violation counter and marks the route unreachable.
+func TestEnforceLatencySLO(t *testing.T) {
+	r := &reconciler{
+		config: Config{
+			MaxLatency: time.Second,
+		},
+	}
+	host := "canary-latency-slo-test.apps.example.com"
+	SetCanaryRouteReachableMetric(host, true)
+
+	// Under the threshold: no violation, reachability untouched.
+	violations := testutil.ToFloat64(CanaryLatencySLOViolations)
+	if r.enforceLatencySLO(host, 500*time.Millisecond) {
+		t.Error("expected a probe under the latency SLO to not be treated as failed")
+	}
+	if value := testutil.ToFloat64(CanaryLatencySLOViolations); value != violations {
+		t.Errorf("expected the violation counter to stay at %v, but got %v", violations, value)
+	}
+	if value := testutil.ToFloat64(CanaryRouteReachable.WithLabelValues(host)); value != 1 {
+		t.Errorf("expected the route to stay reachable under the SLO, but got gauge value %v", value)
+	}
+
+	// Over the threshold: a violation is counted and the route is
+	// marked unreachable.
+	if !r.enforceLatencySLO(host, 2*time.Second) {
+		t.Error("expected a probe over the latency SLO to be treated as failed")
+	}
+	if value := testutil.ToFloat64(CanaryLatencySLOViolations); value != violations+1 {
+		t.Errorf("expected the violation counter to increment to %v, but got %v", violations+1, value)
+	}
+	if value := testutil.ToFloat64(CanaryRouteReachable.WithLabelValues(host)); value != 0 {
+		t.Errorf("expected the route to be marked unreachable over the SLO, but got gauge value %v", value)
+	}
+}
+
+// TestRestrictToRotationPorts verifies that a configured rotation port
+// subset is intersected with the service's actual ports and that empty
+// subsets or empty intersections fall back to the full port list.
+func TestRestrictToRotationPorts(t *testing.T) {
+	service := &corev1.Service{
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{TargetPort: intstr.FromString("8080")},
+				{TargetPort: intstr.FromString("8888")},
+				{TargetPort: intstr.FromString("8889")},
+			},
+		},
+	}
+
+	testCases := []struct {
+		description   string
+		rotationPorts []intstr.IntOrString
+		expectedPorts []intstr.IntOrString
+	}{
+		{
+			description:   "no subset configured uses all service ports",
+			rotationPorts: nil,
+			expectedPorts: []intstr.IntOrString{intstr.FromString("8080"), intstr.FromString("8888"), intstr.FromString("8889")},
+		},
+		{
+			description:   "configured subset restricts the port list",
+			rotationPorts: []intstr.IntOrString{intstr.FromString("8080"), intstr.FromString("8889")},
+			expectedPorts: []intstr.IntOrString{intstr.FromString("8080"), intstr.FromString("8889")},
+		},
+		{
+			description:   "ports missing from the service are dropped from the subset",
+			rotationPorts: []intstr.IntOrString{intstr.FromString("8888"), intstr.FromString("9999")},
+			expectedPorts: []intstr.IntOrString{intstr.FromString("8888")},
+		},
+		{
+			description:   "empty intersection falls back to all service ports",
+			rotationPorts: []intstr.IntOrString{intstr.FromString("9999")},
+			expectedPorts: []intstr.IntOrString{intstr.FromString("8080"), intstr.FromString("8888"), intstr.FromString("8889")},
+		},
+	}
+
+	for _, tc := range testCases {
+		r := &reconciler{
+			config: Config{
+				RotationPorts: tc.rotationPorts,
+			},
+		}
+		restricted := r.restrictToRotationPorts(service)
+		actualPorts := []intstr.IntOrString{}
+		for _, port := range restricted.Spec.Ports {
+			actualPorts = append(actualPorts, port.TargetPort)
+		}
+		if !cmp.Equal(actualPorts, tc.expectedPorts) {
+			t.Errorf("%s: expected rotation ports %v, but got %v", tc.description, tc.expectedPorts, actualPorts)
+		}
+	}
+
+	// The original service's port list is never mutated.
+	if len(service.Spec.Ports) != 3 {
+		t.Errorf("expected the original service to keep 3 ports, but got %d", len(service.Spec.Ports))
+	}
+}
+
+// TestConfigValidate exercises Config.Validate for a valid
+// configuration and each invalid field.
+func TestConfigValidate(t *testing.T) {
+	valid := Config{
+		Namespace:    "openshift-ingress-operator",
+		PollInterval: time.Minute,
+	}
+
+	testCases := []struct {
+		description   string
+		mutate        func(*Config)
+		expectedError string
+	}{
+		{
+			description: "valid configuration",
+			mutate:      func(_ *Config) {},
+		},
+		{
+			description:   "missing namespace",
+			mutate:        func(c *Config) { c.Namespace = "" },
+			expectedError: "Namespace is required",
+		},
+		{
+			description:   "negative poll interval",
+			mutate:        func(c *Config) { c.PollInterval = -time.Second },
+			expectedError: "PollInterval",
+		},
+		{
+			description:   "negative jitter factor",
+			mutate:        func(c *Config) { c.JitterFactor = -0.1 },
+			expectedError: "JitterFactor",
+		},
+		{
+			description:   "quorum fraction over 1",
+			mutate:        func(c *Config) { c.ProbeQuorumFraction = 1.5 },
+			expectedError: "ProbeQuorumFraction",
+		},
+		{
+			description:   "extra probe jitter fraction over 1",
+			mutate:        func(c *Config) { c.ExtraProbeJitterFraction = 2 },
+			expectedError: "ExtraProbeJitterFraction",
+		},
+		{
+			description:   "negative service port count",
+			mutate:        func(c *Config) { c.ServicePortCount = -1 },
+			expectedError: "ServicePortCount",
+		},
+		{
+			description:   "negative failure threshold",
+			mutate:        func(c *Config) { c.FailureThreshold = -1 },
+			expectedError: "FailureThreshold",
+		},
+		{
+			description:   "negative degraded threshold",
+			mutate:        func(c *Config) { c.DegradedThreshold = -1 },
+			expectedError: "DegradedThreshold",
+		},
+	}
+
+	for _, tc := range testCases {
+		config := valid
+		tc.mutate(&config)
+		err := config.Validate()
+		if len(tc.expectedError) == 0 {
+			if err != nil {
+				t.Errorf("%s: Validate returned an unexpected error: %v", tc.description, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: expected Validate to return an error, but it did not", tc.description)
+		} else if !strings.Contains(err.Error(), tc.expectedError) {
+			t.Errorf("%s: expected error to contain %q, but got %q", tc.description, tc.expectedError, err.Error())
+		}
+	}
+}
+
+// TestShouldLogFailure verifies that an unchanged failure reason is
+// logged at most once per failure log window, and logged again when
+// the reason changes, the window elapses, or the canary recovers.
+func TestShouldLogFailure(t *testing.T) {
+	start := time.Now()
+	r := &reconciler{
+		config: Config{
+			FailureLogWindow: 5 * time.Minute,
+		},
+	}
+
+	if !r.shouldLogFailure("probe failed", start) {
+		t.Error("expected the first failure to be logged, but it was suppressed")
+	}
+	for i := 1; i <= 3; i++ {
+		if r.shouldLogFailure("probe failed", start.Add(time.Duration(i)*time.Minute)) {
+			t.Errorf("expected an unchanged failure reason within the window to be suppressed on cycle %d, but it was logged", i)
+		}
+	}
+	if !r.shouldLogFailure("connection refused", start.Add(2*time.Minute)) {
+		t.Error("expected a changed failure reason to be logged, but it was suppressed")
+	}
+	if !r.shouldLogFailure("connection refused", start.Add(8*time.Minute)) {
+		t.Error("expected an unchanged failure reason to be logged again after the window elapsed, but it was suppressed")
+	}
+	r.resetFailureLog()
+	if !r.shouldLogFailure("connection refused", start.Add(9*time.Minute)) {
+		t.Error("expected the first failure after a recovery to be logged, but it was suppressed")
+	}
+}
+
+// TestDesiredCanaryResources verifies that the dry-run resource builder
+// returns the namespace, daemonset, service, and route with the
+// expected names and namespaces, without needing a client.
+func TestDesiredCanaryResources(t *testing.T) {
+	os.Setenv(canaryImageEnvName, "registry.example.com/canary:test")
+	defer os.Unsetenv(canaryImageEnvName)
+
+	objects, err := DesiredCanaryResources(Config{Namespace: "openshift-ingress-operator"})
+	if err != nil {
+		t.Fatalf("DesiredCanaryResources returned an unexpected error: %v", err)
+	}
+	if len(objects) != 4 {
+		t.Fatalf("expected 4 desired canary objects, but got %d", len(objects))
+	}
+
+	namespaceName := controller.DefaultCanaryNamespace
+	expected := []struct {
+		object    interface{}
+		name      string
+		namespace string
+	}{
+		{&corev1.Namespace{}, namespaceName, ""},
+		{&appsv1.DaemonSet{}, controller.CanaryDaemonSetName().Name, namespaceName},
+		{&corev1.Service{}, controller.CanaryServiceName().Name, namespaceName},
+		{&routev1.Route{}, controller.CanaryRouteName().Name, namespaceName},
+	}
+	for i, object := range objects {
+		expectedType := fmt.Sprintf("%T", expected[i].object)
+		if gotType := fmt.Sprintf("%T", object); gotType != expectedType {
+			t.Errorf("expected object %d to have type %s, but got %s", i, expectedType, gotType)
+			continue
+		}
+		if object.GetName() != expected[i].name {
+			t.Errorf("expected object %d to have name %q, but got %q", i, expected[i].name, object.GetName())
+		}
+		if object.GetNamespace() != expected[i].namespace {
+			t.Errorf("expected object %d to have namespace %q, but got %q", i, expected[i].namespace, object.GetNamespace())
+		}
+	}
+
+	daemonset, ok := objects[1].(*appsv1.DaemonSet)
+	if !ok {
+		t.Fatalf("expected object 1 to be a daemonset, but got %T", objects[1])
+	}
+	if image := daemonset.Spec.Template.Spec.Containers[0].Image; image != "registry.example.com/canary:test" {
+		t.Errorf("expected the desired daemonset to carry the resolved canary image, but got %q", image)
+	}
+}
+
+// routeCreateFailingClient wraps a client and fails every route
+// creation to simulate a route ensure failure.
+type routeCreateFailingClient struct {
+	client.Client
+}
+
+func (c *routeCreateFailingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if _, ok := obj.(*routev1.Route); ok {
+		return fmt.Errorf("simulated route creation failure")
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+// TestReconcileErrorMetrics verifies that a failing ensure step
+// increments the reconcile error counter series for that step.
+func TestReconcileErrorMetrics(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "default",
+		},
+	}
+	r := &reconciler{
+		client: &routeCreateFailingClient{fake.NewClientBuilder().WithScheme(operatorclient.GetScheme()).WithObjects(ic).Build()},
+		config: Config{
+			CanaryImage: "openshift/origin-cluster-ingress-operator:latest",
+		},
+	}
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: ic.Namespace,
+			Name:      ic.Name,
+		},
+	}
+
+	routeErrors := testutil.ToFloat64(CanaryReconcileErrors.WithLabelValues("route"))
+	serviceErrors := testutil.ToFloat64(CanaryReconcileErrors.WithLabelValues("service"))
+	if _, err := r.Reconcile(context.TODO(), request); err == nil {
+		t.Fatal("expected Reconcile to return an error when the route cannot be created, but it did not")
+	}
+	if got := testutil.ToFloat64(CanaryReconcileErrors.WithLabelValues("route")); got != routeErrors+1 {
+		t.Errorf("expected the route reconcile error counter to increment from %v to %v, but got %v", routeErrors, routeErrors+1, got)
+	}
+	if got := testutil.ToFloat64(CanaryReconcileErrors.WithLabelValues("service")); got != serviceErrors {
+		t.Errorf("expected the service reconcile error counter to stay at %v, but got %v", serviceErrors, got)
+	}
+}
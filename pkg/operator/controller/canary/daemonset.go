@@ -3,6 +3,9 @@ package canary
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -10,15 +13,42 @@ import (
 	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
 	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
 
+	configv1 "github.com/openshift/api/config/v1"
+
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // ensureCanaryDaemonSet ensures the canary daemonset exists
-func (r *reconciler) ensureCanaryDaemonSet() (bool, *appsv1.DaemonSet, error) {
-	desired := desiredCanaryDaemonSet(r.config.CanaryImage)
-	haveDs, current, err := r.currentCanaryDaemonSet()
+func (r *reconciler) ensureCanaryDaemonSet(ctx context.Context) (bool, *appsv1.DaemonSet, error) {
+	desired := desiredCanaryDaemonSet(r.config.CanaryImage, r.config.ResourceRequirements)
+	// Place the daemonset in the configured canary namespace.
+	desired.Namespace = r.canaryNamespace()
+	// Expose the configured port list on the canary container and tell
+	// the canary server to listen on every port.
+	if r.config.ServicePortCount > 0 {
+		setCanaryContainerPorts(desired, desiredCanaryPorts(r.config))
+	}
+	// Propagate configured pull secrets so that the canary image can
+	// be pulled from registries that require credentials.
+	if len(r.config.ImagePullSecrets) != 0 {
+		desired.Spec.Template.Spec.ImagePullSecrets = r.config.ImagePullSecrets
+	}
+	// During maintenance, schedule no canary pods; the manifest's node
+	// selector is restored once maintenance mode ends.
+	if r.inMaintenanceMode() {
+		setMaintenanceNodeSelector(desired)
+	}
+	// Inject the cluster proxy environment into the canary container
+	// so that the canary backend works in proxied environments.
+	proxy, err := r.currentClusterProxy()
+	if err != nil {
+		return false, nil, err
+	}
+	setProxyEnvVars(desired, proxy)
+	haveDs, current, err := r.currentCanaryDaemonSet(ctx)
 	if err != nil {
 		return false, nil, err
 	}
@@ -28,22 +58,61 @@ func (r *reconciler) ensureCanaryDaemonSet() (bool, *appsv1.DaemonSet, error) {
 		if err := r.createCanaryDaemonSet(desired); err != nil {
 			return false, nil, err
 		}
-		return r.currentCanaryDaemonSet()
+		return r.currentCanaryDaemonSet(ctx)
 	case haveDs:
 		if updated, err := r.updateCanaryDaemonSet(current, desired); err != nil {
 			return true, current, err
 		} else if updated {
-			return r.currentCanaryDaemonSet()
+			return r.currentCanaryDaemonSet(ctx)
 		}
 	}
 
 	return true, current, nil
 }
 
+// currentClusterProxy returns the cluster proxy config, or nil if none
+// exists.
+func (r *reconciler) currentClusterProxy() (*configv1.Proxy, error) {
+	proxy := &configv1.Proxy{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, proxy); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get cluster proxy config: %v", err)
+	}
+	return proxy, nil
+}
+
+// setProxyEnvVars injects the cluster proxy's resolved settings into
+// the canary container's environment. Nothing is injected when no
+// proxy is configured, so the environment converges as the proxy
+// config changes.
+func setProxyEnvVars(daemonset *appsv1.DaemonSet, proxy *configv1.Proxy) {
+	if proxy == nil {
+		return
+	}
+	env := []corev1.EnvVar{}
+	if len(proxy.Status.HTTPProxy) != 0 {
+		env = append(env, corev1.EnvVar{Name: "HTTP_PROXY", Value: proxy.Status.HTTPProxy})
+	}
+	if len(proxy.Status.HTTPSProxy) != 0 {
+		env = append(env, corev1.EnvVar{Name: "HTTPS_PROXY", Value: proxy.Status.HTTPSProxy})
+	}
+	if len(proxy.Status.NoProxy) != 0 {
+		env = append(env, corev1.EnvVar{Name: "NO_PROXY", Value: proxy.Status.NoProxy})
+	}
+	if len(env) == 0 {
+		return
+	}
+	daemonset.Spec.Template.Spec.Containers[0].Env = append(daemonset.Spec.Template.Spec.Containers[0].Env, env...)
+}
+
 // currentCanaryDaemonSet returns the current canary daemonset
-func (r *reconciler) currentCanaryDaemonSet() (bool, *appsv1.DaemonSet, error) {
+func (r *reconciler) currentCanaryDaemonSet(ctx context.Context) (bool, *appsv1.DaemonSet, error) {
 	daemonset := &appsv1.DaemonSet{}
-	if err := r.client.Get(context.TODO(), controller.CanaryDaemonSetName(), daemonset); err != nil {
+	ctx, cancel := apiReadContext(ctx)
+	defer cancel()
+	if err := r.client.Get(ctx, r.canaryDaemonSetName(), daemonset); err != nil {
 		if errors.IsNotFound(err) {
 			return false, nil, nil
 		}
@@ -54,7 +123,8 @@ func (r *reconciler) currentCanaryDaemonSet() (bool, *appsv1.DaemonSet, error) {
 
 // createCanaryDaemonSet creates the given daemonset resource
 func (r *reconciler) createCanaryDaemonSet(daemonset *appsv1.DaemonSet) error {
-	if err := r.client.Create(context.TODO(), daemonset); err != nil {
+	r.applyCanaryFinalizer(daemonset)
+	if err := r.retryCreate(func() error { return r.client.Create(context.TODO(), daemonset) }); err != nil {
 		return fmt.Errorf("failed to create canary daemonset %s/%s: %v", daemonset.Namespace, daemonset.Name, err)
 	}
 
@@ -62,6 +132,24 @@ func (r *reconciler) createCanaryDaemonSet(daemonset *appsv1.DaemonSet) error {
 	return nil
 }
 
+// deleteCanaryDaemonSet deletes the given daemonset resource,
+// tolerating an already-deleted daemonset
+func (r *reconciler) deleteCanaryDaemonSet(ctx context.Context, daemonset *appsv1.DaemonSet) error {
+	if err := r.removeCanaryFinalizer(daemonset); err != nil {
+		return err
+	}
+
+	if err := r.client.Delete(ctx, daemonset); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete canary daemonset %s/%s: %v", daemonset.Namespace, daemonset.Name, err)
+	}
+
+	log.Info("deleted canary daemonset", "namespace", daemonset.Namespace, "name", daemonset.Name)
+	return nil
+}
+
 // updateCanaryDaemonSet updates the canary daemonset if an appropriate change
 // has been detected
 func (r *reconciler) updateCanaryDaemonSet(current, desired *appsv1.DaemonSet) (bool, error) {
@@ -70,16 +158,53 @@ func (r *reconciler) updateCanaryDaemonSet(current, desired *appsv1.DaemonSet) (
 		return false, nil
 	}
 
+	imageChanged := len(current.Spec.Template.Spec.Containers) > 0 && len(updated.Spec.Template.Spec.Containers) > 0 &&
+		current.Spec.Template.Spec.Containers[0].Image != updated.Spec.Template.Spec.Containers[0].Image
+
 	if err := r.client.Update(context.TODO(), updated); err != nil {
 		return false, fmt.Errorf("failed to update canary daemonset %s/%s: %v", updated.Namespace, updated.Name, err)
 	}
 	log.Info("updated canary daemonset", "namespace", updated.Namespace, "name", updated.Name)
+
+	// An image change triggers a rollout of the canary pods, during
+	// which probe failures are expected. Record the rollout so that
+	// the polling loop can suppress reachability flips for a grace
+	// window.
+	if imageChanged {
+		CanaryImageRollouts.Inc()
+		r.recordImageRollout(time.Now())
+	}
+
 	return true, nil
 }
 
+// recordImageRollout records when the canary image was last updated.
+func (r *reconciler) recordImageRollout(now time.Time) {
+	r.imageRolloutMu.Lock()
+	defer r.imageRolloutMu.Unlock()
+	r.lastImageRollout = now
+}
+
+// inImageRolloutGracePeriod returns true if the canary image was
+// updated recently enough that probe failures should be attributed to
+// the resulting rollout rather than to an unreachable route.
+func (r *reconciler) inImageRolloutGracePeriod(now time.Time) bool {
+	r.imageRolloutMu.Lock()
+	defer r.imageRolloutMu.Unlock()
+	if r.lastImageRollout.IsZero() {
+		return false
+	}
+	grace := r.config.ImageRolloutGracePeriod
+	if grace == 0 {
+		grace = defaultImageRolloutGracePeriod
+	}
+	return now.Sub(r.lastImageRollout) < grace
+}
+
 // desiredCanaryDaemonSet returns the desired canary daemonset read in
-// from manifests
-func desiredCanaryDaemonSet(canaryImage string) *appsv1.DaemonSet {
+// from manifests. Resource requests and limits from the manifest are
+// kept unless resources overrides them.
+func desiredCanaryDaemonSet(canaryImage string, resources corev1.ResourceRequirements) *appsv1.DaemonSet {
 	daemonset := manifests.CanaryDaemonSet()
 	name := controller.CanaryDaemonSetName()
 	daemonset.Name = name.Name
@@ -96,15 +221,61 @@ func desiredCanaryDaemonSet(canaryImage string) *appsv1.DaemonSet {
 	daemonset.Spec.Template.Spec.Containers[0].Image = canaryImage
 	daemonset.Spec.Template.Spec.Containers[0].Command = []string{"ingress-operator", CanaryHealthcheckCommand}
 
+	// Apply resource overrides so that the canary can be right-sized
+	// for constrained clusters or clusters with strict LimitRanges.
+	if len(resources.Requests) != 0 || len(resources.Limits) != 0 {
+		daemonset.Spec.Template.Spec.Containers[0].Resources = resources
+	}
+
 	return daemonset
 }
 
-// canaryDaemonSetChanged returns true if current and expected differ by the pod template's
-// node selector, tolerations, or container image reference.
+// setCanaryContainerPorts sets the canary container's port list to
+// match the given service ports and passes the full port list to the
+// canary server through its environment so that every service port has
+// a listener behind it.
+func setCanaryContainerPorts(daemonset *appsv1.DaemonSet, servicePorts []corev1.ServicePort) {
+	containerPorts := make([]corev1.ContainerPort, 0, len(servicePorts))
+	portList := make([]string, 0, len(servicePorts))
+	for _, port := range servicePorts {
+		containerPorts = append(containerPorts, corev1.ContainerPort{
+			ContainerPort: int32(port.TargetPort.IntValue()),
+			Protocol:      corev1.ProtocolTCP,
+		})
+		portList = append(portList, strconv.Itoa(port.TargetPort.IntValue()))
+	}
+	daemonset.Spec.Template.Spec.Containers[0].Ports = containerPorts
+	daemonset.Spec.Template.Spec.Containers[0].Env = append(daemonset.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{
+		Name:  CanaryHealthcheckPortsEnvName,
+		Value: strings.Join(portList, ","),
+	})
+}
+
+// canaryDaemonSetReady returns true if the canary daemonset reports at
+// least one available pod, meaning the canary endpoint has a backend
+// that can serve probe requests.
+func canaryDaemonSetReady(daemonset *appsv1.DaemonSet) bool {
+	return daemonset.Status.NumberAvailable > 0
+}
+
+// canaryDaemonSetChanged returns true if current and expected differ by their
+// labels or by the pod template's labels, node selector, tolerations, or
+// container image reference. Spec.Selector is immutable on daemonsets, so it
+// is deliberately not compared.
 func canaryDaemonSetChanged(current, expected *appsv1.DaemonSet) (bool, *appsv1.DaemonSet) {
 	changed := false
 	updated := current.DeepCopy()
 
+	if !cmp.Equal(current.Labels, expected.Labels, cmpopts.EquateEmpty()) {
+		updated.Labels = expected.Labels
+		changed = true
+	}
+
+	if !cmp.Equal(current.Spec.Template.Labels, expected.Spec.Template.Labels, cmpopts.EquateEmpty()) {
+		updated.Spec.Template.Labels = expected.Spec.Template.Labels
+		changed = true
+	}
+
 	// Update the canary daemonset when the canary server image, command, or container name changes
 	if len(current.Spec.Template.Spec.Containers) > 0 && len(expected.Spec.Template.Spec.Containers) > 0 {
 		if current.Spec.Template.Spec.Containers[0].Image != expected.Spec.Template.Spec.Containers[0].Image {
@@ -119,6 +290,18 @@ func canaryDaemonSetChanged(current, expected *appsv1.DaemonSet) (bool, *appsv1.
 			updated.Spec.Template.Spec.Containers[0].Name = expected.Spec.Template.Spec.Containers[0].Name
 			changed = true
 		}
+		if !cmp.Equal(current.Spec.Template.Spec.Containers[0].Env, expected.Spec.Template.Spec.Containers[0].Env, cmpopts.EquateEmpty()) {
+			updated.Spec.Template.Spec.Containers[0].Env = expected.Spec.Template.Spec.Containers[0].Env
+			changed = true
+		}
+		// Reconcile the declared container ports so that a
+		// ServicePortCount change propagates to an existing daemonset;
+		// the port alignment check compares the service against these
+		// declared ports.
+		if !cmp.Equal(current.Spec.Template.Spec.Containers[0].Ports, expected.Spec.Template.Spec.Containers[0].Ports, cmpopts.EquateEmpty()) {
+			updated.Spec.Template.Spec.Containers[0].Ports = expected.Spec.Template.Spec.Containers[0].Ports
+			changed = true
+		}
 	}
 
 	if !cmp.Equal(current.Spec.Template.Spec.NodeSelector, expected.Spec.Template.Spec.NodeSelector, cmpopts.EquateEmpty()) {
@@ -131,6 +314,11 @@ func canaryDaemonSetChanged(current, expected *appsv1.DaemonSet) (bool, *appsv1.
 		changed = true
 	}
 
+	if !cmp.Equal(current.Spec.Template.Spec.ImagePullSecrets, expected.Spec.Template.Spec.ImagePullSecrets, cmpopts.EquateEmpty()) {
+		updated.Spec.Template.Spec.ImagePullSecrets = expected.Spec.Template.Spec.ImagePullSecrets
+		changed = true
+	}
+
 	if !changed {
 		return false, nil
 	}
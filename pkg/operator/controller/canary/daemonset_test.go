@@ -1,22 +1,30 @@
 package canary
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
+	configv1 "github.com/openshift/api/config/v1"
+
 	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
 	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestDesiredCanaryDaemonSet(t *testing.T) {
 	// canaryImageName is the ingress-operator image
 	canaryImageName := "openshift/origin-cluster-ingress-operator:latest"
-	daemonset := desiredCanaryDaemonSet(canaryImageName)
+	daemonset := desiredCanaryDaemonSet(canaryImageName, corev1.ResourceRequirements{})
 
 	expectedDaemonSetName := controller.CanaryDaemonSetName()
 
@@ -81,6 +89,30 @@ func TestDesiredCanaryDaemonSet(t *testing.T) {
 	}
 }
 
+func TestDesiredCanaryDaemonSetResourceOverride(t *testing.T) {
+	defaultResources := desiredCanaryDaemonSet("", corev1.ResourceRequirements{}).Spec.Template.Spec.Containers[0].Resources
+
+	override := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("20m"),
+			corev1.ResourceMemory: resource.MustParse("64Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("128Mi"),
+		},
+	}
+	daemonset := desiredCanaryDaemonSet("", override)
+	if got := daemonset.Spec.Template.Spec.Containers[0].Resources; !cmp.Equal(got, override) {
+		t.Errorf("expected daemonset container resources to be %v, but got %v", override, got)
+	}
+
+	// An unset override keeps the manifest defaults.
+	daemonset = desiredCanaryDaemonSet("", corev1.ResourceRequirements{})
+	if got := daemonset.Spec.Template.Spec.Containers[0].Resources; !cmp.Equal(got, defaultResources) {
+		t.Errorf("expected daemonset container resources to be %v, but got %v", defaultResources, got)
+	}
+}
+
 func TestCanaryDaemonsetChanged(t *testing.T) {
 	testCases := []struct {
 		description string
@@ -92,6 +124,20 @@ func TestCanaryDaemonsetChanged(t *testing.T) {
 			mutate:      func(_ *appsv1.DaemonSet) {},
 			expect:      false,
 		},
+		{
+			description: "if the daemonset labels change",
+			mutate: func(ds *appsv1.DaemonSet) {
+				ds.Labels = map[string]string{"test": "labels"}
+			},
+			expect: true,
+		},
+		{
+			description: "if the pod template labels change",
+			mutate: func(ds *appsv1.DaemonSet) {
+				ds.Spec.Template.Labels = map[string]string{"test": "labels"}
+			},
+			expect: true,
+		},
 		{
 			description: "if pod template node selector changes",
 			mutate: func(ds *appsv1.DaemonSet) {
@@ -137,6 +183,24 @@ func TestCanaryDaemonsetChanged(t *testing.T) {
 			},
 			expect: true,
 		},
+		{
+			description: "if canary container env changes",
+			mutate: func(ds *appsv1.DaemonSet) {
+				ds.Spec.Template.Spec.Containers[0].Env = []corev1.EnvVar{
+					{Name: "HTTP_PROXY", Value: "http://proxy.example.com:3128"},
+				}
+			},
+			expect: true,
+		},
+		{
+			description: "if canary container ports change",
+			mutate: func(ds *appsv1.DaemonSet) {
+				ds.Spec.Template.Spec.Containers[0].Ports = []corev1.ContainerPort{
+					{ContainerPort: 9090, Protocol: corev1.ProtocolTCP},
+				}
+			},
+			expect: true,
+		},
 		{
 			description: "if canary server container name changes",
 			mutate: func(ds *appsv1.DaemonSet) {
@@ -147,7 +211,7 @@ func TestCanaryDaemonsetChanged(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		original := desiredCanaryDaemonSet("")
+		original := desiredCanaryDaemonSet("", corev1.ResourceRequirements{})
 		mutated := original.DeepCopy()
 		tc.mutate(mutated)
 		if changed, updated := canaryDaemonSetChanged(original, mutated); changed != tc.expect {
@@ -159,3 +223,140 @@ func TestCanaryDaemonsetChanged(t *testing.T) {
 		}
 	}
 }
+
+func TestCanaryDaemonSetReady(t *testing.T) {
+	testCases := []struct {
+		description   string
+		status        appsv1.DaemonSetStatus
+		expectedReady bool
+	}{
+		{
+			description: "daemonset with no available pods",
+			status: appsv1.DaemonSetStatus{
+				DesiredNumberScheduled: 3,
+				NumberAvailable:        0,
+			},
+			expectedReady: false,
+		},
+		{
+			description: "daemonset with one available pod",
+			status: appsv1.DaemonSetStatus{
+				DesiredNumberScheduled: 3,
+				NumberAvailable:        1,
+			},
+			expectedReady: true,
+		},
+		{
+			description: "daemonset with all pods available",
+			status: appsv1.DaemonSetStatus{
+				DesiredNumberScheduled: 3,
+				NumberAvailable:        3,
+			},
+			expectedReady: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		daemonset := &appsv1.DaemonSet{
+			Status: tc.status,
+		}
+		if ready := canaryDaemonSetReady(daemonset); ready != tc.expectedReady {
+			t.Errorf("%s: expected canaryDaemonSetReady to return %v, but got %v", tc.description, tc.expectedReady, ready)
+		}
+	}
+}
+
+func TestSetProxyEnvVars(t *testing.T) {
+	testCases := []struct {
+		description string
+		proxy       *configv1.Proxy
+		expectedEnv []corev1.EnvVar
+	}{
+		{
+			description: "no cluster proxy",
+			proxy:       nil,
+			expectedEnv: nil,
+		},
+		{
+			description: "cluster proxy without resolved settings",
+			proxy:       &configv1.Proxy{},
+			expectedEnv: nil,
+		},
+		{
+			description: "cluster proxy with resolved settings",
+			proxy: &configv1.Proxy{
+				Status: configv1.ProxyStatus{
+					HTTPProxy:  "http://proxy.example.com:3128",
+					HTTPSProxy: "https://proxy.example.com:3128",
+					NoProxy:    ".cluster.local,.svc",
+				},
+			},
+			expectedEnv: []corev1.EnvVar{
+				{Name: "HTTP_PROXY", Value: "http://proxy.example.com:3128"},
+				{Name: "HTTPS_PROXY", Value: "https://proxy.example.com:3128"},
+				{Name: "NO_PROXY", Value: ".cluster.local,.svc"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		daemonset := desiredCanaryDaemonSet("", corev1.ResourceRequirements{})
+		setProxyEnvVars(daemonset, tc.proxy)
+		if env := daemonset.Spec.Template.Spec.Containers[0].Env; !cmp.Equal(env, tc.expectedEnv) {
+			t.Errorf("%s: expected container env %v, but got %v", tc.description, tc.expectedEnv, env)
+		}
+	}
+}
+
+func TestInImageRolloutGracePeriod(t *testing.T) {
+	r := &reconciler{
+		config: Config{
+			ImageRolloutGracePeriod: 3 * time.Minute,
+		},
+	}
+
+	now := time.Now()
+	if r.inImageRolloutGracePeriod(now) {
+		t.Error("expected no grace period before any image rollout has been recorded")
+	}
+
+	r.recordImageRollout(now)
+	if !r.inImageRolloutGracePeriod(now.Add(time.Minute)) {
+		t.Error("expected probe failures to be suppressed within the image rollout grace period")
+	}
+	if r.inImageRolloutGracePeriod(now.Add(5 * time.Minute)) {
+		t.Error("expected probe failures to not be suppressed after the image rollout grace period")
+	}
+}
+
+// TestCanaryDaemonSetImagePullSecrets verifies that configured image
+// pull secrets propagate to the canary daemonset's pod template.
+func TestCanaryDaemonSetImagePullSecrets(t *testing.T) {
+	pullSecrets := []corev1.LocalObjectReference{
+		{Name: "canary-pull-secret"},
+	}
+	r := &reconciler{
+		client: fake.NewClientBuilder().WithScheme(operatorclient.GetScheme()).Build(),
+		config: Config{
+			CanaryImage:      "openshift/origin-cluster-ingress-operator:latest",
+			ImagePullSecrets: pullSecrets,
+		},
+	}
+
+	haveDs, daemonset, err := r.ensureCanaryDaemonSet(context.TODO())
+	if err != nil {
+		t.Fatalf("failed to ensure canary daemonset: %v", err)
+	} else if !haveDs {
+		t.Fatal("expected canary daemonset to exist")
+	}
+	if !cmp.Equal(daemonset.Spec.Template.Spec.ImagePullSecrets, pullSecrets) {
+		t.Errorf("expected pod template image pull secrets %v, but got %v", pullSecrets, daemonset.Spec.Template.Spec.ImagePullSecrets)
+	}
+
+	// No pull secrets are set by default.
+	r.config.ImagePullSecrets = nil
+	daemonset = desiredCanaryDaemonSet("", corev1.ResourceRequirements{})
+	if len(daemonset.Spec.Template.Spec.ImagePullSecrets) != 0 {
+		t.Errorf("expected no pod template image pull secrets by default, but got %v", daemonset.Spec.Template.Spec.ImagePullSecrets)
+	}
+}
@@ -0,0 +1,38 @@
+package canary
+
+import (
+	operatorv1 "github.com/openshift/api/operator/v1"
+)
+
+const (
+	// CanaryDisabledAnnotation is an annotation on the default ingress
+	// controller that disables the canary entirely, for clusters that
+	// cannot or do not want to run the canary (e.g. air-gapped clusters
+	// or clusters with strict egress policies). When the annotation has
+	// a value of "true", the canary resources are deleted and canary
+	// checks are suspended; when the annotation is removed or set to
+	// any other value, the resources and the checks are restored.
+	CanaryDisabledAnnotation = "ingress.operator.openshift.io/canary-disabled"
+)
+
+// canaryDisableRequested returns true if the given ingress controller
+// is annotated to disable the canary.
+func canaryDisableRequested(ic *operatorv1.IngressController) bool {
+	return ic.Annotations[CanaryDisabledAnnotation] == "true"
+}
+
+// setCanaryDisabled records whether the canary is disabled by
+// annotation.
+func (r *reconciler) setCanaryDisabled(disabled bool) {
+	r.disabledMu.Lock()
+	defer r.disabledMu.Unlock()
+	r.canaryDisabled = disabled
+}
+
+// isCanaryDisabled returns true if the canary is disabled by
+// annotation.
+func (r *reconciler) isCanaryDisabled() bool {
+	r.disabledMu.Lock()
+	defer r.disabledMu.Unlock()
+	return r.canaryDisabled
+}
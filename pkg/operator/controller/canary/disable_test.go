@@ -0,0 +1,102 @@
+package canary
+
+import (
+	"context"
+	"testing"
+
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// TestCanaryDisabledAnnotation verifies that annotating the default
+// ingress controller with the disable annotation tears down the canary
+// resources and suspends checks, and that removing the annotation
+// restores them.
+func TestCanaryDisabledAnnotation(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-ingress-operator",
+			Name:      "default",
+		},
+	}
+	r := &reconciler{
+		client: fake.NewClientBuilder().WithScheme(operatorclient.GetScheme()).WithObjects(ic).Build(),
+		config: Config{
+			CanaryImage: "openshift/origin-cluster-ingress-operator:latest",
+		},
+	}
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: ic.Namespace,
+			Name:      ic.Name,
+		},
+	}
+
+	// An unannotated ingress controller gets the canary resources.
+	if _, err := r.Reconcile(context.TODO(), request); err != nil {
+		t.Fatalf("Reconcile returned an unexpected error: %v", err)
+	}
+	if haveRoute, _, err := r.currentCanaryRoute(context.TODO()); err != nil {
+		t.Fatalf("failed to get canary route: %v", err)
+	} else if !haveRoute {
+		t.Fatal("expected the canary route to exist before the canary is disabled")
+	}
+	if r.isCanaryDisabled() {
+		t.Fatal("expected the canary to not be disabled before the annotation is set")
+	}
+
+	// Setting the disable annotation tears the resources down and
+	// suspends checks.
+	if err := r.client.Get(context.TODO(), request.NamespacedName, ic); err != nil {
+		t.Fatalf("failed to get ingress controller: %v", err)
+	}
+	ic.Annotations = map[string]string{
+		CanaryDisabledAnnotation: "true",
+	}
+	if err := r.client.Update(context.TODO(), ic); err != nil {
+		t.Fatalf("failed to update ingress controller: %v", err)
+	}
+	if _, err := r.Reconcile(context.TODO(), request); err != nil {
+		t.Fatalf("Reconcile returned an unexpected error: %v", err)
+	}
+	if haveRoute, _, err := r.currentCanaryRoute(context.TODO()); err != nil {
+		t.Fatalf("failed to get canary route: %v", err)
+	} else if haveRoute {
+		t.Fatal("expected the canary route to be deleted while the canary is disabled")
+	}
+	if haveDs, _, err := r.currentCanaryDaemonSet(context.TODO()); err != nil {
+		t.Fatalf("failed to get canary daemonset: %v", err)
+	} else if haveDs {
+		t.Fatal("expected the canary daemonset to be deleted while the canary is disabled")
+	}
+	if !r.isCanaryDisabled() {
+		t.Fatal("expected the canary to be disabled while the annotation is set")
+	}
+
+	// Removing the annotation restores the resources and the checks.
+	if err := r.client.Get(context.TODO(), request.NamespacedName, ic); err != nil {
+		t.Fatalf("failed to get ingress controller: %v", err)
+	}
+	delete(ic.Annotations, CanaryDisabledAnnotation)
+	if err := r.client.Update(context.TODO(), ic); err != nil {
+		t.Fatalf("failed to update ingress controller: %v", err)
+	}
+	if _, err := r.Reconcile(context.TODO(), request); err != nil {
+		t.Fatalf("Reconcile returned an unexpected error: %v", err)
+	}
+	if haveRoute, _, err := r.currentCanaryRoute(context.TODO()); err != nil {
+		t.Fatalf("failed to get canary route: %v", err)
+	} else if !haveRoute {
+		t.Fatal("expected the canary route to be restored after the canary is re-enabled")
+	}
+	if r.isCanaryDisabled() {
+		t.Fatal("expected the canary to not be disabled after the annotation is removed")
+	}
+}
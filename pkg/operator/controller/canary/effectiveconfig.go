@@ -0,0 +1,118 @@
+package canary
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+// canaryEffectiveConfigAnnotation is an annotation on the canary route
+// that carries a compact JSON summary of the probe configuration the
+// operator is actually using after defaulting, so that the effective
+// config can be inspected without reading operator logs.
+const canaryEffectiveConfigAnnotation = "ingress.operator.openshift.io/canary-effective-config"
+
+// effectiveProbeConfig is the defaulted view of the canary probe
+// configuration that is surfaced on the canary route.
+type effectiveProbeConfig struct {
+	// ProbeTimeout is the effective per-probe timeout.
+	ProbeTimeout string `json:"probeTimeout"`
+	// CheckInterval is the polling interval between canary checks.
+	CheckInterval string `json:"checkInterval"`
+	// ExpectedBody is the effective expected response body.
+	ExpectedBody string `json:"expectedBody"`
+	// MaxProbeRedirects is the effective redirect cap.
+	MaxProbeRedirects int `json:"maxProbeRedirects"`
+	// MaxDateSkew is the effective Date header skew allowance, when
+	// Date header validation is enabled.
+	MaxDateSkew string `json:"maxDateSkew,omitempty"`
+	// Modes lists the optional probe modes that are enabled.
+	Modes []string `json:"modes,omitempty"`
+}
+
+// effectiveConfig returns the reconciler's probe configuration with
+// defaults applied.
+func (r *reconciler) effectiveConfig() effectiveProbeConfig {
+	maxRedirects := r.config.MaxProbeRedirects
+	if maxRedirects == 0 {
+		maxRedirects = defaultMaxProbeRedirects
+	}
+
+	probeTimeout := r.config.ProbeTimeout
+	if probeTimeout == 0 {
+		probeTimeout = defaultProbeTimeout
+	}
+
+	expectedBody := r.config.ExpectedBody
+	if len(expectedBody) == 0 {
+		expectedBody = CanaryHealthcheckResponse
+	}
+
+	checkInterval := r.config.PollInterval
+	if checkInterval == 0 {
+		checkInterval = canaryCheckFrequency
+	}
+
+	effective := effectiveProbeConfig{
+		ProbeTimeout:      probeTimeout.String(),
+		CheckInterval:     checkInterval.String(),
+		ExpectedBody:      expectedBody,
+		MaxProbeRedirects: maxRedirects,
+	}
+
+	if r.config.ValidateDateHeader {
+		maxSkew := r.config.MaxDateSkew
+		if maxSkew == 0 {
+			maxSkew = defaultMaxDateSkew
+		}
+		effective.MaxDateSkew = maxSkew.String()
+	}
+
+	modes := []string{}
+	for mode, enabled := range map[string]bool{
+		"balance":       r.config.BalanceProbe,
+		"compression":   r.config.CompressionProbe,
+		"date-header":   r.config.ValidateDateHeader,
+		"idle-timeout":  r.config.IdleTimeoutProbe,
+		"method-echo":   r.config.MethodEchoProbe,
+		"negative-host": r.config.NegativeHostProbe,
+		"pipelining":    r.config.PipeliningProbe,
+		"slow-header":   r.config.SlowHeaderProbe,
+	} {
+		if enabled {
+			modes = append(modes, mode)
+		}
+	}
+	sort.Strings(modes)
+	effective.Modes = modes
+
+	return effective
+}
+
+// ensureEffectiveConfigAnnotation ensures the canary route's effective
+// config annotation reflects the current defaulted configuration,
+// updating the route when the annotation drifts.
+func (r *reconciler) ensureEffectiveConfigAnnotation(route *routev1.Route) error {
+	data, err := json.Marshal(r.effectiveConfig())
+	if err != nil {
+		return fmt.Errorf("failed to marshal canary effective config: %v", err)
+	}
+
+	if route.Annotations[canaryEffectiveConfigAnnotation] == string(data) {
+		return nil
+	}
+
+	updated := route.DeepCopy()
+	if updated.Annotations == nil {
+		updated.Annotations = map[string]string{}
+	}
+	updated.Annotations[canaryEffectiveConfigAnnotation] = string(data)
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update canary effective config annotation on route %s/%s: %v", updated.Namespace, updated.Name, err)
+	}
+	log.Info("updated canary effective config annotation", "namespace", updated.Namespace, "name", updated.Name)
+	return nil
+}
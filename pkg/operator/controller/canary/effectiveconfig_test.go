@@ -0,0 +1,107 @@
+package canary
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	routev1 "github.com/openshift/api/route/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestEnsureEffectiveConfigAnnotation(t *testing.T) {
+	r := &reconciler{
+		client: fake.NewClientBuilder().WithScheme(operatorclient.GetScheme()).Build(),
+		config: Config{
+			CompressionProbe:   true,
+			ValidateDateHeader: true,
+		},
+	}
+
+	route := &routev1.Route{}
+	name := controller.CanaryRouteName()
+	route.Namespace = name.Namespace
+	route.Name = name.Name
+	if err := r.createCanaryRoute(context.TODO(), route); err != nil {
+		t.Fatalf("failed to create canary route: %v", err)
+	}
+
+	if err := r.ensureEffectiveConfigAnnotation(route); err != nil {
+		t.Fatalf("failed to ensure effective config annotation: %v", err)
+	}
+
+	updated := &routev1.Route{}
+	if err := r.client.Get(context.TODO(), name, updated); err != nil {
+		t.Fatalf("failed to get canary route: %v", err)
+	}
+
+	var effective effectiveProbeConfig
+	if err := json.Unmarshal([]byte(updated.Annotations[canaryEffectiveConfigAnnotation]), &effective); err != nil {
+		t.Fatalf("expected effective config annotation to be valid JSON: %v", err)
+	}
+
+	expected := effectiveProbeConfig{
+		ProbeTimeout:      defaultProbeTimeout.String(),
+		CheckInterval:     canaryCheckFrequency.String(),
+		ExpectedBody:      CanaryHealthcheckResponse,
+		MaxProbeRedirects: defaultMaxProbeRedirects,
+		MaxDateSkew:       defaultMaxDateSkew.String(),
+		Modes:             []string{"compression", "date-header"},
+	}
+	if !cmp.Equal(effective, expected) {
+		t.Errorf("expected effective config %+v, but got %+v", expected, effective)
+	}
+
+	// A second ensure with the same config is a no-op.
+	if err := r.ensureEffectiveConfigAnnotation(updated); err != nil {
+		t.Fatalf("failed to re-ensure effective config annotation: %v", err)
+	}
+	unchanged := &routev1.Route{}
+	if err := r.client.Get(context.TODO(), name, unchanged); err != nil {
+		t.Fatalf("failed to get canary route: %v", err)
+	}
+	if unchanged.ResourceVersion != updated.ResourceVersion {
+		t.Error("expected an unchanged effective config to not update the route")
+	}
+}
+
+// TestEffectiveConfigCheckInterval verifies that the effective config
+// reports the configured poll interval, falling back to the default
+// check frequency when none is configured.
+func TestEffectiveConfigCheckInterval(t *testing.T) {
+	testCases := []struct {
+		description      string
+		pollInterval     time.Duration
+		expectedInterval string
+	}{
+		{
+			description:      "default check interval",
+			expectedInterval: canaryCheckFrequency.String(),
+		},
+		{
+			description:      "configured poll interval",
+			pollInterval:     30 * time.Second,
+			expectedInterval: (30 * time.Second).String(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r := &reconciler{
+				config: Config{
+					PollInterval: tc.pollInterval,
+				},
+			}
+			if effective := r.effectiveConfig(); effective.CheckInterval != tc.expectedInterval {
+				t.Errorf("expected check interval %q, but got %q", tc.expectedInterval, effective.CheckInterval)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package canary
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+// expectContinueFallbackWindow is how long the 100-continue probe waits
+// for the interim 100 response before sending the request body anyway,
+// per RFC 7231 section 5.1.1.
+const expectContinueFallbackWindow = 1 * time.Second
+
+// probeExpectContinue sends a request with Expect: 100-continue and a
+// bounded continue window, verifying that the request still completes
+// when the 100 interim response is withheld — the client proceeds per
+// spec, and the router must handle the late body gracefully.
+func (r *reconciler) probeExpectContinue(route *routev1.Route) error {
+	options := r.probeOptionsForHost(route.Spec.Host)
+	transport := r.newRouteProbeTransport(route)
+	transport.ExpectContinueTimeout = expectContinueFallbackWindow
+	client := &http.Client{
+		Timeout:   options.Timeout,
+		Transport: transport,
+	}
+
+	return sendExpectContinueProbe(client, canaryRouteURL(routeProbeScheme(route), route.Spec.Host))
+}
+
+// sendExpectContinueProbe sends a request with Expect: 100-continue
+// using the given client and verifies that the request completes.
+func sendExpectContinueProbe(client *http.Client, url string) error {
+	request, err := http.NewRequest("POST", url, strings.NewReader("canary"))
+	if err != nil {
+		return fmt.Errorf("error creating canary 100-continue probe request: %v", err)
+	}
+	request.Header.Set("Expect", "100-continue")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("error sending canary 100-continue probe request to %q: %v", url, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("unexpected status code %d from canary 100-continue probe", response.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package canary
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSendExpectContinueProbeWithheldContinue verifies that a request
+// with Expect: 100-continue completes via the timeout fallback when the
+// backend never sends the interim 100 response.
+func TestSendExpectContinueProbeWithheldContinue(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to create listener: %v", err)
+	}
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// Read the request headers but deliberately withhold the
+		// interim 100 Continue response, forcing the client to
+		// fall back to sending the body after its continue window
+		// expires.
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if line == "\r\n" {
+				break
+			}
+		}
+
+		// Read the body that the client sends via the fallback.
+		body := make([]byte, len("canary"))
+		if _, err := io.ReadFull(reader, body); err != nil {
+			return
+		}
+
+		io.WriteString(conn, "HTTP/1.1 200 OK\r\nContent-Length: 0\r\nConnection: close\r\n\r\n")
+	}()
+
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			ExpectContinueTimeout: 100 * time.Millisecond,
+		},
+	}
+
+	if err := sendExpectContinueProbe(client, "http://"+listener.Addr().String()); err != nil {
+		t.Errorf("expected 100-continue probe to complete via the fallback, but got: %v", err)
+	}
+}
+
+// TestSendExpectContinueProbeImmediateContinue verifies that the probe
+// also passes against a backend that sends the 100 response promptly.
+func TestSendExpectContinueProbeImmediateContinue(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to create listener: %v", err)
+	}
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if line == "\r\n" {
+				break
+			}
+		}
+
+		io.WriteString(conn, "HTTP/1.1 100 Continue\r\n\r\n")
+
+		body := make([]byte, len("canary"))
+		if _, err := io.ReadFull(reader, body); err != nil {
+			return
+		}
+		if !strings.EqualFold(string(body), "canary") {
+			io.WriteString(conn, "HTTP/1.1 400 Bad Request\r\nContent-Length: 0\r\nConnection: close\r\n\r\n")
+			return
+		}
+
+		io.WriteString(conn, "HTTP/1.1 200 OK\r\nContent-Length: 0\r\nConnection: close\r\n\r\n")
+	}()
+
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			ExpectContinueTimeout: 1 * time.Second,
+		},
+	}
+
+	if err := sendExpectContinueProbe(client, "http://"+listener.Addr().String()); err != nil {
+		t.Errorf("expected 100-continue probe to pass, but got: %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package canary
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/util/slice"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// applyCanaryFinalizer adds the configured canary finalizer to the
+// given object's metadata, if a finalizer is configured. The finalizer
+// lets the reconciler orchestrate teardown ordering rather than racing
+// garbage collection.
+func (r *reconciler) applyCanaryFinalizer(obj metav1.Object) {
+	finalizer := r.config.CanaryFinalizer
+	if len(finalizer) == 0 {
+		return
+	}
+	if !slice.ContainsString(obj.GetFinalizers(), finalizer) {
+		obj.SetFinalizers(append(obj.GetFinalizers(), finalizer))
+	}
+}
+
+// removeCanaryFinalizer strips the configured canary finalizer from the
+// given object and updates it, unblocking deletion during orchestrated
+// teardown. It is a no-op if no finalizer is configured or the object
+// does not carry it.
+func (r *reconciler) removeCanaryFinalizer(obj client.Object) error {
+	finalizer := r.config.CanaryFinalizer
+	if len(finalizer) == 0 {
+		return nil
+	}
+	if !slice.ContainsString(obj.GetFinalizers(), finalizer) {
+		return nil
+	}
+	updated := obj.DeepCopyObject().(client.Object)
+	updated.SetFinalizers(slice.RemoveString(updated.GetFinalizers(), finalizer))
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to remove finalizer %q from %s/%s: %v", finalizer, obj.GetNamespace(), obj.GetName(), err)
+	}
+	log.Info("removed canary finalizer", "namespace", obj.GetNamespace(), "name", obj.GetName())
+	return nil
+}
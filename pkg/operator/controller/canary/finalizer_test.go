@@ -0,0 +1,74 @@
+package canary
+
+import (
+	"context"
+	"testing"
+
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+	"github.com/openshift/cluster-ingress-operator/pkg/util/slice"
+
+	routev1 "github.com/openshift/api/route/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCanaryFinalizer(t *testing.T) {
+	finalizer := "ingress.operator.openshift.io/canary"
+	r := &reconciler{
+		client: fake.NewClientBuilder().WithScheme(operatorclient.GetScheme()).Build(),
+		config: Config{
+			CanaryFinalizer: finalizer,
+		},
+	}
+
+	route := &routev1.Route{}
+	name := controller.CanaryRouteName()
+	route.Namespace = name.Namespace
+	route.Name = name.Name
+
+	if err := r.createCanaryRoute(context.TODO(), route); err != nil {
+		t.Fatalf("failed to create canary route: %v", err)
+	}
+
+	created := &routev1.Route{}
+	if err := r.client.Get(context.TODO(), name, created); err != nil {
+		t.Fatalf("failed to get canary route: %v", err)
+	}
+	if !slice.ContainsString(created.Finalizers, finalizer) {
+		t.Fatalf("expected canary route to have finalizer %q, but got %v", finalizer, created.Finalizers)
+	}
+
+	if _, err := r.deleteCanaryRoute(context.TODO(), created); err != nil {
+		t.Fatalf("failed to delete canary route: %v", err)
+	}
+	if haveRoute, _, err := r.currentCanaryRoute(context.TODO()); err != nil {
+		t.Fatalf("failed to get canary route: %v", err)
+	} else if haveRoute {
+		t.Fatal("expected canary route to be deleted after finalizer removal")
+	}
+}
+
+func TestCanaryFinalizerDisabled(t *testing.T) {
+	r := &reconciler{
+		client: fake.NewClientBuilder().WithScheme(operatorclient.GetScheme()).Build(),
+		config: Config{},
+	}
+
+	route := &routev1.Route{}
+	name := controller.CanaryRouteName()
+	route.Namespace = name.Namespace
+	route.Name = name.Name
+
+	if err := r.createCanaryRoute(context.TODO(), route); err != nil {
+		t.Fatalf("failed to create canary route: %v", err)
+	}
+
+	created := &routev1.Route{}
+	if err := r.client.Get(context.TODO(), name, created); err != nil {
+		t.Fatalf("failed to get canary route: %v", err)
+	}
+	if len(created.Finalizers) != 0 {
+		t.Fatalf("expected canary route to have no finalizers, but got %v", created.Finalizers)
+	}
+}
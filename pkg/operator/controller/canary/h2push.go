@@ -0,0 +1,66 @@
+package canary
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+// probeH2NoPush sends a probe over HTTP/2 and verifies that the router
+// does not attempt server push. The HTTP/2 client advertises
+// SETTINGS_ENABLE_PUSH=0, so any PUSH_PROMISE from the router violates
+// the protocol and surfaces as a connection-level protocol error, which
+// the probe flags. A router that does not negotiate HTTP/2 has nothing
+// to validate.
+func (r *reconciler) probeH2NoPush(route *routev1.Route) error {
+	options := r.probeOptionsForHost(route.Spec.Host)
+	transport := r.newRouteProbeTransport(route)
+	transport.ForceAttemptHTTP2 = true
+	client := &http.Client{
+		Timeout:   options.Timeout,
+		Transport: transport,
+	}
+
+	response, err := client.Get(canaryRouteURL(routeProbeScheme(route), route.Spec.Host))
+	if err != nil {
+		if isUnexpectedPushError(err) {
+			return fmt.Errorf("router attempted HTTP/2 server push despite the client disabling it: %v", err)
+		}
+		return fmt.Errorf("error sending canary HTTP/2 push probe request to %q: %v", route.Spec.Host, err)
+	}
+	defer response.Body.Close()
+
+	if response.ProtoMajor != 2 {
+		// The router did not negotiate HTTP/2, so push behavior
+		// cannot be validated.
+		return nil
+	}
+
+	// Pushes can also arrive while the body is being read.
+	if _, err := io.Copy(ioutil.Discard, response.Body); err != nil {
+		if isUnexpectedPushError(err) {
+			return fmt.Errorf("router attempted HTTP/2 server push despite the client disabling it: %v", err)
+		}
+		return fmt.Errorf("error reading canary HTTP/2 push probe response body: %v", err)
+	}
+
+	return nil
+}
+
+// isUnexpectedPushError returns true if the given error indicates that
+// the server sent a PUSH_PROMISE even though the client disabled push.
+// Only push-specific messages are matched; the generic PROTOCOL_ERROR
+// code also appears in unrelated HTTP/2 errors such as flow-control
+// violations and malformed frames.
+func isUnexpectedPushError(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := err.Error()
+	return strings.Contains(message, "PUSH_PROMISE") ||
+		strings.Contains(message, "pushed stream")
+}
@@ -0,0 +1,69 @@
+package canary
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProbeH2NoPush(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	r := &reconciler{
+		config: Config{
+			H2NoPushProbe: true,
+		},
+	}
+
+	route := routeForServer(server, "8080")
+	if err := r.probeH2NoPush(route); err != nil {
+		t.Errorf("expected HTTP/2 push probe to pass against a non-pushing server, but got: %v", err)
+	}
+}
+
+func TestIsUnexpectedPushError(t *testing.T) {
+	testCases := []struct {
+		description string
+		err         error
+		expect      bool
+	}{
+		{
+			description: "nil error",
+			err:         nil,
+			expect:      false,
+		},
+		{
+			description: "push promise protocol violation",
+			err:         errors.New("http2: connection error: PROTOCOL_ERROR: received PUSH_PROMISE with push disabled"),
+			expect:      true,
+		},
+		{
+			description: "pushed stream violation",
+			err:         errors.New("http2: server sent pushed stream without permission"),
+			expect:      true,
+		},
+		{
+			description: "unrelated protocol error",
+			err:         errors.New("http2: connection error: PROTOCOL_ERROR: received window update on idle stream"),
+			expect:      false,
+		},
+		{
+			description: "unrelated error",
+			err:         errors.New("connection refused"),
+			expect:      false,
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := isUnexpectedPushError(tc.err); got != tc.expect {
+			t.Errorf("%s: expected isUnexpectedPushError to be %t, but got %t", tc.description, tc.expect, got)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package canary
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// probeHealth records the outcome of the most recent canary probe so
+// that the health endpoint can serve the canary's verdict without
+// requiring external monitors to scrape Prometheus.
+var probeHealth struct {
+	mu sync.Mutex
+	// observed is true once at least one probe has completed.
+	observed bool
+	// lastErr is the error from the most recent probe, or nil if the
+	// probe succeeded.
+	lastErr error
+}
+
+// recordProbeHealth records the outcome of a canary probe for the
+// health endpoint.
+func recordProbeHealth(err error) {
+	probeHealth.mu.Lock()
+	defer probeHealth.mu.Unlock()
+	probeHealth.observed = true
+	probeHealth.lastErr = err
+}
+
+// canaryHealthzHandler serves the most recent canary probe outcome:
+// 200 when the probe succeeded, and 503 with the error text in the
+// body when it failed or no probe has completed yet.
+func canaryHealthzHandler(w http.ResponseWriter, _ *http.Request) {
+	probeHealth.mu.Lock()
+	observed, lastErr := probeHealth.observed, probeHealth.lastErr
+	probeHealth.mu.Unlock()
+
+	switch {
+	case !observed:
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintln(w, "no canary probe has completed yet")
+	case lastErr != nil:
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, "canary check failing: %v\n", lastErr)
+	default:
+		fmt.Fprintln(w, "canary check passing")
+	}
+}
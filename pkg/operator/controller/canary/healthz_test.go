@@ -0,0 +1,40 @@
+package canary
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestCanaryHealthzHandler verifies that the health endpoint reflects
+// the most recent canary probe outcome.
+func TestCanaryHealthzHandler(t *testing.T) {
+	// No probe has completed yet.
+	recorder := httptest.NewRecorder()
+	canaryHealthzHandler(recorder, httptest.NewRequest("GET", "/canary/healthz", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d before any probe has completed, but got %d", http.StatusServiceUnavailable, recorder.Code)
+	}
+
+	// The most recent probe succeeded.
+	recordProbeHealth(nil)
+	recorder = httptest.NewRecorder()
+	canaryHealthzHandler(recorder, httptest.NewRequest("GET", "/canary/healthz", nil))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("expected status %d after a successful probe, but got %d", http.StatusOK, recorder.Code)
+	}
+
+	// The most recent probe failed.
+	probeErr := errors.New("canary route unreachable")
+	recordProbeHealth(probeErr)
+	recorder = httptest.NewRecorder()
+	canaryHealthzHandler(recorder, httptest.NewRequest("GET", "/canary/healthz", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d after a failed probe, but got %d", http.StatusServiceUnavailable, recorder.Code)
+	}
+	if !strings.Contains(recorder.Body.String(), probeErr.Error()) {
+		t.Errorf("expected the response body to contain the probe error %q, but got %q", probeErr.Error(), recorder.Body.String())
+	}
+}
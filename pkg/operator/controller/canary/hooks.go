@@ -0,0 +1,23 @@
+package canary
+
+// ProbeResultHook is a callback invoked after each canary probe with
+// the probed host, the probe result, and the probe's error, if any.
+type ProbeResultHook func(host string, result ProbeResult, err error)
+
+// invokeProbeResultHooks invokes the configured probe result hooks with
+// the given probe outcome. Each hook runs on its own goroutine with
+// panic recovery so that a misbehaving hook can neither block nor crash
+// the polling loop.
+func (r *reconciler) invokeProbeResultHooks(host string, result *ProbeResult, err error) {
+	for _, hook := range r.config.ProbeResultHooks {
+		hook := hook
+		go func() {
+			defer func() {
+				if panicked := recover(); panicked != nil {
+					log.Error(nil, "canary probe result hook panicked", "panic", panicked)
+				}
+			}()
+			hook(host, *result, err)
+		}()
+	}
+}
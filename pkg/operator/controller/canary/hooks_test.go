@@ -0,0 +1,48 @@
+package canary
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInvokeProbeResultHooks(t *testing.T) {
+	type hookCall struct {
+		host   string
+		result ProbeResult
+		err    error
+	}
+
+	calls := make(chan hookCall, 2)
+	r := &reconciler{
+		config: Config{
+			ProbeResultHooks: []ProbeResultHook{
+				func(host string, result ProbeResult, err error) {
+					panic("misbehaving hook")
+				},
+				func(host string, result ProbeResult, err error) {
+					calls <- hookCall{host: host, result: result, err: err}
+				},
+			},
+		},
+	}
+
+	probeErr := errors.New("probe failed")
+	result := &ProbeResult{BackendAddr: "10.0.0.1:8080"}
+	r.invokeProbeResultHooks("canary.example.com", result, probeErr)
+
+	select {
+	case call := <-calls:
+		if call.host != "canary.example.com" {
+			t.Errorf("expected hook host %q, but got %q", "canary.example.com", call.host)
+		}
+		if call.result.BackendAddr != result.BackendAddr {
+			t.Errorf("expected hook result backend address %q, but got %q", result.BackendAddr, call.result.BackendAddr)
+		}
+		if call.err != probeErr {
+			t.Errorf("expected hook error %v, but got %v", probeErr, call.err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for probe result hook to be invoked")
+	}
+}
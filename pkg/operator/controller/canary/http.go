@@ -1,41 +1,532 @@
 package canary
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"net/http/httptrace"
+	"net/url"
 	"os"
 	"strings"
 	"time"
 
 	routev1 "github.com/openshift/api/route/v1"
 
+	operatorcontroller "github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
 	"github.com/tcnksm/go-httpstat"
 )
 
 const (
 	echoServerPortAckHeader = "x-request-port"
+
+	// defaultMaxProbeRedirects is how many redirects a canary probe
+	// follows before giving up when Config.MaxProbeRedirects is unset.
+	defaultMaxProbeRedirects = 3
+
+	// defaultMaxDateSkew is the maximum allowed skew between the
+	// response's Date header and the operator's clock when
+	// Config.MaxDateSkew is unset.
+	defaultMaxDateSkew = 5 * time.Minute
+
+	// defaultProbeTimeout is the overall timeout for a single probe
+	// request when no per-host override is configured.
+	defaultProbeTimeout = 10 * time.Second
+
+	// defaultProbeUserAgent is the User-Agent header value that canary
+	// probes send when Config.UserAgent is unset, so that canary
+	// traffic is identifiable in access logs.
+	defaultProbeUserAgent = "openshift-ingress-canary"
+)
+
+// ProbeOptions holds per-host probe settings. Zero-valued fields fall
+// back to the global defaults.
+type ProbeOptions struct {
+	// Timeout is the overall timeout for a single probe request.
+	// Defaults to 10 seconds when unset.
+	Timeout time.Duration
+	// ExpectedBody is the string that the probe expects the response
+	// body to contain. Defaults to CanaryHealthcheckResponse when
+	// unset.
+	ExpectedBody string
+}
+
+// portEchoHeader returns the name of the response header that the
+// canary backend echoes the request port in, falling back to the
+// default header when none is configured.
+func (r *reconciler) portEchoHeader() string {
+	if len(r.config.PortEchoHeader) != 0 {
+		return r.config.PortEchoHeader
+	}
+	return echoServerPortAckHeader
+}
+
+// probeOptionsForHost returns the probe options for the given host,
+// applying any per-host override from the configuration and filling in
+// the configured or built-in global defaults for unset fields.
+func (r *reconciler) probeOptionsForHost(host string) ProbeOptions {
+	options := r.config.HostProbeOverrides[host]
+	if options.Timeout == 0 {
+		options.Timeout = r.config.ProbeTimeout
+	}
+	if options.Timeout == 0 {
+		options.Timeout = defaultProbeTimeout
+	}
+	if len(options.ExpectedBody) == 0 {
+		options.ExpectedBody = r.config.ExpectedBody
+	}
+	if len(options.ExpectedBody) == 0 {
+		options.ExpectedBody = CanaryHealthcheckResponse
+	}
+	return options
+}
+
+var (
+	// errMissingDateHeader is returned when Date header validation is
+	// enabled and the canary response has no Date header.
+	errMissingDateHeader = errors.New("canary response is missing the Date header")
+	// errDateSkewExceeded is returned when Date header validation is
+	// enabled and the response's Date header is outside the allowed
+	// skew of the operator's clock.
+	errDateSkewExceeded = errors.New("canary response Date header exceeds the allowed clock skew")
+	// errTooManyRedirects is returned when a canary probe is redirected
+	// more times than the configured redirect cap allows.
+	errTooManyRedirects = errors.New("too many redirects")
+)
+
+// Exported sentinel errors classify canary probe failures so that
+// callers can react to specific failure modes with errors.Is rather
+// than matching error strings.
+var (
+	// ErrCanaryDNSResolution indicates the probe failed to resolve the
+	// canary route host.
+	ErrCanaryDNSResolution = errors.New("canary host DNS resolution failed")
+	// ErrCanaryRequestTimeout indicates the probe timed out, either at
+	// the client or with a 408 response.
+	ErrCanaryRequestTimeout = errors.New("canary request timed out")
+	// ErrCanaryWrongPort indicates the probe reached the canary
+	// backend on a different port than the route specifies, which may
+	// mean the router is wedged.
+	ErrCanaryWrongPort = errors.New("canary request received on the wrong port")
+	// ErrCanaryBodyMismatch indicates the canary response body was
+	// empty or did not contain the expected content.
+	ErrCanaryBodyMismatch = errors.New("canary response body mismatch")
+	// ErrCanaryRouteUnavailable indicates the router answered the
+	// probe with 503, meaning the canary route is not being served.
+	ErrCanaryRouteUnavailable = errors.New("canary route unavailable")
+	// ErrCanaryUnexpectedStatus indicates the probe received a status
+	// code with no more specific classification.
+	ErrCanaryUnexpectedStatus = errors.New("canary response has an unexpected status code")
 )
 
+// isDNSResolutionError returns true if the given canary check error was
+// caused by a failure to resolve the route host.
+func isDNSResolutionError(err error) bool {
+	return errors.Is(err, ErrCanaryDNSResolution)
+}
+
+// RedirectHop records a single hop in a redirect chain
+// followed during a canary probe.
+type RedirectHop struct {
+	// URL is the location that the probe was redirected to.
+	URL string
+	// StatusCode is the status code of the response that
+	// triggered the redirect.
+	StatusCode int
+}
+
+// ProbeResult is a snapshot of a single canary probe.
+type ProbeResult struct {
+	// RedirectChain records each redirect hop followed during
+	// the probe, in order, when redirect chain recording is enabled.
+	RedirectChain []RedirectHop
+	// BackendAddr is the remote address of the connection that served
+	// the probe, for correlating failures with specific pods or nodes.
+	BackendAddr string
+	// WrongPortEcho is true if the probe reached the backend on a
+	// different port than the route specifies.
+	WrongPortEcho bool
+	// EchoedPort is the port the backend reported receiving the probe
+	// on when WrongPortEcho is true.
+	EchoedPort string
+	// ExpectedPort is the route's target port at the time of the probe
+	// when WrongPortEcho is true.
+	ExpectedPort string
+	// Latency is the probe's total measured time, so that callers can
+	// log it or apply a latency SLO. It is zero if the probe failed
+	// before the response body was read.
+	Latency time.Duration
+}
+
+// routeUsesTLS returns true if the given route is TLS terminated.
+func routeUsesTLS(route *routev1.Route) bool {
+	return route.Spec.TLS != nil && len(route.Spec.TLS.Termination) != 0
+}
+
+// routeProbeScheme returns the URL scheme for probing the given route.
+// Probes go over https whenever the route is TLS terminated
+// (edge/reencrypt/passthrough) so that the TLS path is actually
+// validated. Some clusters that expose the default ingress controller
+// via an external load balancer drop all traffic on port 80, in which
+// case redirecting insecure traffic is not possible. See
+// https://bugzilla.redhat.com/show_bug.cgi?id=1934773.
+func routeProbeScheme(route *routev1.Route) string {
+	if routeUsesTLS(route) {
+		return "https"
+	}
+	return "http"
+}
+
+// newRouteProbeTransport returns a probe transport for the given
+// route, carrying the probe TLS configuration when the route is TLS
+// terminated. Auxiliary probes build on this shared template so that
+// the configured CA bundle or skip-verify escape hatch, the
+// cluster-proxy handling, and the probe source address apply to every
+// probe, not just the main canary check.
+func (r *reconciler) newRouteProbeTransport(route *routev1.Route) *http.Transport {
+	if routeUsesTLS(route) {
+		return r.newProbeTransport(r.probeTLSConfigForRoute(route))
+	}
+	return r.newProbeTransport(nil)
+}
+
+// newRouteProbeClient returns a client for probing the given route
+// with the shared probe transport.
+func (r *reconciler) newRouteProbeClient(route *routev1.Route, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: r.newRouteProbeTransport(route),
+	}
+}
+
+// dialRouteProbeConn opens a raw connection to the given route's
+// endpoint for probes that drive the protocol exchange by hand, using
+// the probe TLS configuration when the route is TLS terminated and
+// plain TCP otherwise. A proxy cannot carry a raw exchange, so these
+// probes always connect directly.
+func (r *reconciler) dialRouteProbeConn(route *routev1.Route) (net.Conn, error) {
+	addr := route.Spec.Host
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		port := "80"
+		if routeUsesTLS(route) {
+			port = "443"
+		}
+		addr = net.JoinHostPort(addr, port)
+	}
+	dialer := r.probeDialer()
+	if routeUsesTLS(route) {
+		return tls.DialWithDialer(dialer, "tcp", addr, r.probeTLSConfigForRoute(route))
+	}
+	return dialer.Dial("tcp", addr)
+}
+
+// newProbeTransport returns a transport with the common canary probe
+// settings and the given TLS configuration.
+func (r *reconciler) newProbeTransport(tlsConfig *tls.Config) *http.Transport {
+	transport := &http.Transport{
+		// Honor the cluster-wide proxy configuration, including its
+		// noProxy list, falling back to the pod's environment proxy
+		// settings when no cluster proxy is configured.
+		Proxy:           r.probeProxy(),
+		TLSClientConfig: tlsConfig,
+	}
+	dialer := r.probeDialer()
+	// When a target IP is configured, connect to it directly while
+	// preserving the route host for the Host header and SNI. This
+	// bypasses DNS entirely, isolating the load balancer and router
+	// path from name resolution.
+	if len(r.config.ProbeTargetIP) != 0 {
+		transport.DialContext = dialToTargetIP(dialer, r.config.ProbeTargetIP)
+	} else if dialer.LocalAddr != nil {
+		transport.DialContext = dialer.DialContext
+	}
+	return transport
+}
+
+// probeDialer returns the dialer that probe transports dial out with.
+// When a probe source address is configured, the dialer binds to it so
+// that probes egress over the chosen interface on multi-homed nodes.
+// Otherwise the OS chooses the source address.
+func (r *reconciler) probeDialer() *net.Dialer {
+	dialer := &net.Dialer{}
+	if len(r.config.ProbeSourceAddress) != 0 {
+		if ip := net.ParseIP(r.config.ProbeSourceAddress); ip != nil {
+			dialer.LocalAddr = &net.TCPAddr{IP: ip}
+		} else {
+			log.Error(fmt.Errorf("invalid probe source address %q", r.config.ProbeSourceAddress), "letting the OS choose the probe source address")
+		}
+	}
+	return dialer
+}
+
+// newHTTPClient returns a client for probing routes with no TLS
+// termination.
+func (r *reconciler) newHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: r.newProbeTransport(nil),
+	}
+}
+
+// newHTTPSClient returns a client for probing TLS-terminated routes
+// with the given TLS configuration.
+func (r *reconciler) newHTTPSClient(timeout time.Duration, tlsConfig *tls.Config) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: r.newProbeTransport(tlsConfig),
+	}
+}
+
+// probeTLSConfig returns the TLS configuration used for probing the
+// canary route. An explicitly configured escape hatch skips
+// verification entirely. Otherwise, when a router CA bundle is
+// configured (directly or through a ConfigMap reference), the router's
+// serving certificate is verified against the system roots extended
+// with the bundle. With no bundle, verification is skipped because the
+// default router certificate may be self signed. See
+// https://bugzilla.redhat.com/show_bug.cgi?id=1932401.
+func (r *reconciler) probeTLSConfig() *tls.Config {
+	if r.config.ProbeInsecureSkipVerify {
+		return &tls.Config{InsecureSkipVerify: true}
+	}
+	if bundle := r.probeRouterCABundle(); len(bundle) != 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if pool.AppendCertsFromPEM(bundle) {
+			return &tls.Config{RootCAs: pool}
+		}
+		log.Error(fmt.Errorf("failed to parse probe router CA bundle"), "falling back to skipping certificate verification")
+	}
+	return &tls.Config{InsecureSkipVerify: true}
+}
+
+// probeTLSConfigForRoute returns the TLS configuration for probing the
+// given route, with the route host set as the TLS ServerName (SNI) so
+// that the router selects the right certificate and verification runs
+// against the route host even when the probe connects to an IP
+// directly. This matters for passthrough and reencrypt routes, whose
+// backends select certificates by SNI.
+func (r *reconciler) probeTLSConfigForRoute(route *routev1.Route) *tls.Config {
+	tlsConfig := r.probeTLSConfig()
+	host := route.Spec.Host
+	// A host carrying a port (as test servers do) is not a valid SNI
+	// value; send only the host part.
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	tlsConfig.ServerName = host
+	return tlsConfig
+}
+
+// probeRouterCABundle returns the PEM-encoded CA bundle used to verify
+// the router's serving certificate, preferring explicitly configured
+// PEM bytes and otherwise reading the referenced ConfigMap's
+// "ca-bundle.crt" key from the openshift-config namespace.
+func (r *reconciler) probeRouterCABundle() []byte {
+	if len(r.config.ProbeRouterCABundle) != 0 {
+		return r.config.ProbeRouterCABundle
+	}
+	if len(r.config.ProbeRouterCABundleConfigMap) == 0 || r.client == nil {
+		return nil
+	}
+	configmap := &corev1.ConfigMap{}
+	name := types.NamespacedName{
+		Namespace: operatorcontroller.GlobalUserSpecifiedConfigNamespace,
+		Name:      r.config.ProbeRouterCABundleConfigMap,
+	}
+	ctx, cancel := apiReadContext(context.Background())
+	defer cancel()
+	if err := r.client.Get(ctx, name, configmap); err != nil {
+		log.Error(err, "failed to get probe router CA bundle configmap", "namespace", name.Namespace, "name", name.Name)
+		return nil
+	}
+	return []byte(configmap.Data["ca-bundle.crt"])
+}
+
+// isTLSHandshakeError returns true if the given request error was
+// caused by a failure to establish the TLS connection rather than by
+// the HTTP exchange itself.
+func isTLSHandshakeError(err error) bool {
+	if err == nil {
+		return false
+	}
+	recordErr := tls.RecordHeaderError{}
+	if errors.As(err, &recordErr) {
+		return true
+	}
+	var certErr *tls.CertificateVerificationError
+	if errors.As(err, &certErr) {
+		return true
+	}
+	unknownAuthorityErr := x509.UnknownAuthorityError{}
+	if errors.As(err, &unknownAuthorityErr) {
+		return true
+	}
+	hostnameErr := x509.HostnameError{}
+	if errors.As(err, &hostnameErr) {
+		return true
+	}
+	return strings.Contains(err.Error(), "tls:")
+}
+
+// canaryRouteURL builds the probe URL for the given scheme and route
+// host. Route hosts are usually DNS names, but an IPv6 literal must be
+// bracket-wrapped to form a valid URL, and a host that already carries
+// a port (or brackets) is preserved as is.
+func canaryRouteURL(scheme, host string) string {
+	u := url.URL{
+		Scheme: scheme,
+		Host:   host,
+	}
+	// A bare IPv6 literal needs brackets; hosts with a port or
+	// existing brackets parse as-is.
+	if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+		u.Host = "[" + host + "]"
+	}
+	return u.String()
+}
+
+// probePathForRoute returns the URL path to probe for the given route,
+// preferring a per-route probe path annotation over the configured
+// default.
+func (r *reconciler) probePathForRoute(route *routev1.Route) string {
+	if path, ok := route.Annotations[CanaryProbePathAnnotation]; ok && len(path) != 0 {
+		return path
+	}
+	return r.config.ProbePath
+}
+
+// canaryProbeURL builds the full probe URL for the given scheme,
+// host, and path.
+func canaryProbeURL(scheme, host, path string) string {
+	probeURL := canaryRouteURL(scheme, host)
+	if len(path) != 0 && path != "/" {
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		probeURL += path
+	}
+	return probeURL
+}
+
+// newProbeRequest builds the canary probe request for the given URL
+// from the configured method and optional body, defaulting to a GET
+// with no body, which is what the default canary image expects.
+func (r *reconciler) newProbeRequest(ctx context.Context, probeURL string) (*http.Request, error) {
+	method := r.config.ProbeMethod
+	if len(method) == 0 {
+		method = http.MethodGet
+	}
+
+	var body io.Reader
+	if len(r.config.ProbeBody) != 0 {
+		body = strings.NewReader(r.config.ProbeBody)
+	}
+
+	return http.NewRequestWithContext(ctx, method, probeURL, body)
+}
+
 // probeRouteEndpoint probes the given route's host
-// and returns an error when applicable.
-func probeRouteEndpoint(route *routev1.Route) error {
+// and returns a snapshot of the probe along with an
+// error when applicable. The probe request is bound to the given
+// context so that an in-flight probe is abandoned when the polling
+// loop shuts down.
+func (r *reconciler) probeRouteEndpoint(ctx context.Context, route *routev1.Route) (*ProbeResult, error) {
+	probeResult := &ProbeResult{}
+
+	// A subdomain route leaves Spec.Host for the router to assign via
+	// status, so fall back to the admitted host before giving up.
+	if len(route.Spec.Host) == 0 {
+		if host := admittedRouteHost(route); len(host) != 0 {
+			route = route.DeepCopy()
+			route.Spec.Host = host
+		}
+	}
 	if len(route.Spec.Host) == 0 {
-		return fmt.Errorf("route.Spec.Host is empty, cannot test route")
+		return probeResult, fmt.Errorf("route.Spec.Host is empty, cannot test route")
+	}
+
+	// A route without a port (manual edit or a manifest regression)
+	// cannot be validated against the port echo; fail the probe
+	// clearly rather than panicking on the nil dereference below. The
+	// next reconcile repairs the port from the canary service.
+	if route.Spec.Port == nil {
+		CanaryRouteMisconfigured.Inc()
+		return probeResult, fmt.Errorf("route %s/%s has no Spec.Port, cannot test route", route.Namespace, route.Name)
+	}
+
+	options := r.probeOptionsForHost(route.Spec.Host)
+	client := r.newRouteProbeClient(route, options.Timeout)
+
+	probeURL := canaryProbeURL(routeProbeScheme(route), route.Spec.Host, r.probePathForRoute(route))
+	return r.probeEndpoint(ctx, probeURL, options.ExpectedBody, route.Spec.Port.TargetPort.String(), client)
+}
+
+// probeEndpoint sends a canary probe to the given URL with the given
+// client and validates the response against the expected body and,
+// when expectedPort is non-empty, the echoed request port. It is the
+// route-agnostic core of probeRouteEndpoint, reusable for probing
+// arbitrary endpoints and testable against a plain httptest server.
+// The given client's CheckRedirect is overridden to cap how many
+// redirects the probe follows.
+func (r *reconciler) probeEndpoint(ctx context.Context, probeURL, expectedBody, expectedPort string, client *http.Client) (*ProbeResult, error) {
+	probeResult := &ProbeResult{}
+
+	// Derive the host for error messages and metric labels from the
+	// probe URL.
+	host := probeURL
+	if parsed, err := url.Parse(probeURL); err == nil && len(parsed.Host) != 0 {
+		host = parsed.Host
 	}
 
-	// Create HTTP request
-	// Use https now that the canary route uses edge termination.
-	// Some clusters that expose the default ingress controller
-	// via an external load balancer drop all traffic on port 80,
-	// in which case redirecting insecure traffic is not possible.
-	// See https://bugzilla.redhat.com/show_bug.cgi?id=1934773.
-	request, err := http.NewRequest("GET", "https://"+route.Spec.Host, nil)
+	request, err := r.newProbeRequest(ctx, probeURL)
 	if err != nil {
-		return fmt.Errorf("error creating canary HTTP request %v: %v", request, err)
+		return probeResult, fmt.Errorf("error creating canary HTTP request %v: %v", request, err)
+	}
+
+	// Identify canary traffic in router and backend access logs so
+	// that operators can filter it out of their log analytics.
+	userAgent := r.config.UserAgent
+	if len(userAgent) == 0 {
+		userAgent = defaultProbeUserAgent
+	}
+	request.Header.Set("User-Agent", userAgent)
+
+	// When the compression probe is enabled, explicitly request a
+	// gzipped response so that the router's (or backend's) response
+	// compression can be validated below. Compression is requested
+	// explicitly rather than through the transport so that the raw,
+	// still-compressed body is returned for validation.
+	if r.config.CompressionProbe {
+		request.Header.Set("Accept-Encoding", "gzip")
+	}
+
+	// When trace exemplars are enabled, generate a fresh W3C
+	// traceparent per probe and propagate it so that the probe's
+	// latency observation can carry the trace ID as an exemplar.
+	traceID := ""
+	if r.config.TraceExemplars {
+		traceparent, id, err := newTraceparent()
+		if err != nil {
+			return probeResult, fmt.Errorf("error generating traceparent for canary HTTP request: %v", err)
+		}
+		request.Header.Set("traceparent", traceparent)
+		traceID = id
 	}
 
 	// Create HTTP result
@@ -43,42 +534,71 @@ func probeRouteEndpoint(route *routev1.Route) error {
 	result := &httpstat.Result{}
 
 	// Get request context
-	ctx := httpstat.WithHTTPStat(request.Context(), result)
-	request = request.WithContext(ctx)
+	ctx = httpstat.WithHTTPStat(request.Context(), result)
 
-	// Send the HTTP request
-	timeout, _ := time.ParseDuration("10s")
-	client := &http.Client{
-		Timeout: timeout,
-		// The canary route uses edge termination and the
-		// default router certificate may be self signed, so
-		// skip certificate verification here. See
-		// https://bugzilla.redhat.com/show_bug.cgi?id=1932401.
-		// TODO: Add the router's certificate to the HTTP client
-		// so we can enable TLS verification.
-		Transport: &http.Transport{
-			// Use the cluster-wide proxy if it is available in the
-			// pod's environment.
-			Proxy:           http.ProxyFromEnvironment,
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	// Capture the remote address of the connection that serves the
+	// probe so that failures can be correlated with specific pods or
+	// nodes. Client traces compose, so this does not disturb the
+	// httpstat instrumentation.
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			probeResult.BackendAddr = info.Conn.RemoteAddr().String()
 		},
 	}
+	ctx = httptrace.WithClientTrace(ctx, trace)
+	request = request.WithContext(ctx)
+
+	// Cap how many redirects the probe follows, and optionally record
+	// each hop of the redirect chain for debugging. The canary route
+	// uses InsecureEdgeTerminationPolicy: Redirect, so redirects are
+	// expected for insecure requests, but an unbounded chain indicates
+	// a router misconfiguration.
+	maxRedirects := r.config.MaxProbeRedirects
+	if maxRedirects == 0 {
+		maxRedirects = defaultMaxProbeRedirects
+	}
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if r.config.RecordRedirectChain {
+			probeResult.RedirectChain = append(probeResult.RedirectChain, RedirectHop{
+				URL:        req.URL.String(),
+				StatusCode: req.Response.StatusCode,
+			})
+		}
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("%w: stopped after %d redirects", errTooManyRedirects, maxRedirects)
+		}
+		return nil
+	}
+
 	response, err := client.Do(request)
 
 	if err != nil {
+		// Surface a redirect loop distinctly: the probe followed the
+		// router's redirects up to the cap without ever reaching the
+		// canary endpoint.
+		if errors.Is(err, errTooManyRedirects) {
+			return probeResult, fmt.Errorf("error sending canary HTTP request to %q: %v", host, err)
+		}
 		// Check if err is a DNS error
 		dnsErr := &net.DNSError{}
 		if errors.As(err, &dnsErr) {
 			// Handle DNS error
-			CanaryRouteDNSError.WithLabelValues(route.Spec.Host, dnsErr.Server).Inc()
-			return fmt.Errorf("error sending canary HTTP request: DNS error: %v", err)
+			CanaryRouteDNSError.WithLabelValues(host, dnsErr.Server).Inc()
+			CanaryDNSFailures.Inc()
+			return probeResult, fmt.Errorf("%w: error sending canary HTTP request: %v", ErrCanaryDNSResolution, err)
 		}
 		// Check if err is a timeout error
 		if os.IsTimeout(err) {
 			// Handle timeout error
-			return fmt.Errorf("error sending canary HTTP Request: Timeout: %v", err)
+			return probeResult, fmt.Errorf("%w: error sending canary HTTP request: %v", ErrCanaryRequestTimeout, err)
+		}
+		// Surface TLS handshake failures distinctly so that a
+		// certificate or handshake problem is not buried in a
+		// generic request error.
+		if isTLSHandshakeError(err) {
+			return probeResult, fmt.Errorf("error establishing TLS connection to %q: %v", host, err)
 		}
-		return fmt.Errorf("error sending canary HTTP request to %q: %v", route.Spec.Host, err)
+		return probeResult, fmt.Errorf("error sending canary HTTP request to %q: %v", host, err)
 	}
 
 	// Close response body even if read fails
@@ -87,53 +607,473 @@ func probeRouteEndpoint(route *routev1.Route) error {
 	// Read response body
 	bodyBytes, err := ioutil.ReadAll(response.Body)
 	if err != nil {
-		return fmt.Errorf("error reading canary response body: %v", err)
+		return probeResult, fmt.Errorf("error reading canary response body: %v", err)
+	}
+	// If the response was compressed, verify that the body
+	// decompresses correctly before checking its contents.
+	if r.config.CompressionProbe && response.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err := gzip.NewReader(bytes.NewReader(bodyBytes))
+		if err != nil {
+			return probeResult, fmt.Errorf("error creating gzip reader for canary response body: %v", err)
+		}
+		decompressed, err := ioutil.ReadAll(gzipReader)
+		if err != nil {
+			return probeResult, fmt.Errorf("error decompressing canary response body: %v", err)
+		}
+		if err := gzipReader.Close(); err != nil {
+			return probeResult, fmt.Errorf("error closing gzip reader for canary response body: %v", err)
+		}
+		bodyBytes = decompressed
 	}
+
 	body := string(bodyBytes)
 	t := time.Now()
 	// Mark request as finished
 	result.End(t)
 	totalTime := result.Total(t)
+	probeResult.Latency = totalTime
+
+	if r.config.Verbose {
+		r.logVerboseProbeDiagnostics(host, result, result.ContentTransfer(t), totalTime, response.StatusCode, len(bodyBytes))
+	}
+
+	// Evaluate per-phase timing budgets, if configured, so that a
+	// slow probe is attributed to a specific request phase rather
+	// than a single overall timeout.
+	if r.config.PhaseBudgets != (PhaseBudgets{}) {
+		if err := checkPhaseBudgets(result, result.ContentTransfer(t), r.config.PhaseBudgets); err != nil {
+			return probeResult, err
+		}
+	}
 
 	// Verify body contents
 	if len(body) == 0 {
-		return fmt.Errorf("expected canary response body to not be empty")
+		return probeResult, fmt.Errorf("%w: expected canary response body to not be empty", ErrCanaryBodyMismatch)
 	}
 
-	if !strings.Contains(body, CanaryHealthcheckResponse) {
-		return fmt.Errorf("expected canary request body to contain %q", CanaryHealthcheckResponse)
+	if !strings.Contains(body, expectedBody) {
+		return probeResult, fmt.Errorf("%w: expected canary request body to contain %q", ErrCanaryBodyMismatch, expectedBody)
 	}
 
-	// Verify that the request was received on the correct port
-	recPort := response.Header.Get(echoServerPortAckHeader)
-	if len(recPort) == 0 {
-		return fmt.Errorf("expected %q header in canary response to have a nonempty value", echoServerPortAckHeader)
+	// Verify that the request was received on the correct port, when a
+	// port to check against was given.
+	recPort := response.Header.Get(r.portEchoHeader())
+	if len(expectedPort) != 0 && len(recPort) == 0 {
+		return probeResult, fmt.Errorf("expected %q header in canary response to have a nonempty value", r.portEchoHeader())
 	}
-	routePortStr := route.Spec.Port.TargetPort.String()
-	if routePortStr != recPort {
+	if len(expectedPort) != 0 && expectedPort != recPort {
 		// router wedged, register in metrics counter
 		CanaryEndpointWrongPortEcho.Inc()
-		return fmt.Errorf("canary request received on port %s, but route specifies %v", recPort, routePortStr)
+		probeResult.WrongPortEcho = true
+		probeResult.EchoedPort = recPort
+		probeResult.ExpectedPort = expectedPort
+		return probeResult, fmt.Errorf("%w: canary request received on port %s, but route specifies %v", ErrCanaryWrongPort, recPort, expectedPort)
+	}
+
+	// Optionally verify that the response's Date header is present
+	// and within the allowed skew of the operator's clock. A missing
+	// or badly-skewed Date header can indicate a misbehaving
+	// intermediary or a clock problem.
+	if r.config.ValidateDateHeader {
+		maxSkew := r.config.MaxDateSkew
+		if maxSkew == 0 {
+			maxSkew = defaultMaxDateSkew
+		}
+		if err := checkDateHeader(response.Header.Get("Date"), time.Now(), maxSkew); err != nil {
+			return probeResult, err
+		}
 	}
 
-	// Check status code
-	switch status := response.StatusCode; status {
-	case http.StatusOK:
+	// Check status code. Any code in the accepted set counts as a
+	// successful check; the specific messages below are preserved for
+	// the non-accepted cases.
+	switch status := response.StatusCode; {
+	case statusCodeAccepted(status, r.config.AcceptableStatusCodes):
 		// Register total time in metrics (use milliseconds)
-		CanaryRequestTime.WithLabelValues(route.Spec.Host).Observe(float64(totalTime.Milliseconds()))
-	case http.StatusRequestTimeout:
-		return fmt.Errorf("status code %d: request timed out", status)
-	case http.StatusServiceUnavailable:
-		return fmt.Errorf("status code %d: Canary route not available via router", status)
-	case http.StatusBadGateway:
-		return fmt.Errorf("status code %d: bad gateway", status)
-	case http.StatusInternalServerError:
-		return fmt.Errorf("status code %d: server error", status)
-	case http.StatusTooManyRequests:
-		return fmt.Errorf("status code %d: too many requests", status)
+		observeCanaryRequestTime(r.metricHostLabel(host), float64(totalTime.Milliseconds()), traceID)
+		// Register the per-phase breakdown that httpstat measured.
+		observeCanaryRequestPhases(r.metricHostLabel(host), result)
+	case status == http.StatusRequestTimeout:
+		return probeResult, fmt.Errorf("%w: status code %d: request timed out", ErrCanaryRequestTimeout, status)
+	case status == http.StatusServiceUnavailable:
+		return probeResult, fmt.Errorf("%w: status code %d: Canary route not available via router", ErrCanaryRouteUnavailable, status)
+	case status == http.StatusBadGateway:
+		return probeResult, fmt.Errorf("%w: status code %d: bad gateway", ErrCanaryUnexpectedStatus, status)
+	case status == http.StatusInternalServerError:
+		return probeResult, fmt.Errorf("%w: status code %d: server error", ErrCanaryUnexpectedStatus, status)
+	case status == http.StatusTooManyRequests:
+		return probeResult, fmt.Errorf("%w: status code %d: too many requests", ErrCanaryUnexpectedStatus, status)
 	default:
-		return fmt.Errorf("unexpected status code: %d", status)
+		// The probe's client follows redirects itself, so a terminal
+		// 3xx means the redirect chain was cut short, for example by a
+		// redirect response with no Location header.
+		if status >= http.StatusMultipleChoices && status < http.StatusBadRequest {
+			return probeResult, fmt.Errorf("%w: unexpected redirect status code %d: canary request was redirected but never reached the canary endpoint", ErrCanaryUnexpectedStatus, status)
+		}
+		return probeResult, fmt.Errorf("%w: unexpected status code: %d", ErrCanaryUnexpectedStatus, status)
+	}
+
+	return probeResult, nil
+}
+
+// verboseProbeLogLevel is the verbosity level that per-probe phase
+// diagnostics are logged at, keeping them out of the default output.
+const verboseProbeLogLevel = 2
+
+// logVerboseProbeDiagnostics logs the per-phase timing breakdown that
+// httpstat measured for a probe, along with the response status and
+// body length, for field debugging of slow or flaky canary checks.
+func (r *reconciler) logVerboseProbeDiagnostics(host string, result *httpstat.Result, contentTransfer, total time.Duration, statusCode, bodyLength int) {
+	log.V(verboseProbeLogLevel).Info("canary probe diagnostics",
+		"host", host,
+		"dnsLookup", result.DNSLookup,
+		"tcpConnection", result.TCPConnection,
+		"tlsHandshake", result.TLSHandshake,
+		"serverProcessing", result.ServerProcessing,
+		"contentTransfer", contentTransfer,
+		"total", total,
+		"status", statusCode,
+		"bodyLength", bodyLength,
+	)
+}
+
+// statusCodeAccepted returns true if the given response status code is
+// in the accepted set. An empty set accepts only 200.
+func statusCodeAccepted(status int, accepted []int) bool {
+	if len(accepted) == 0 {
+		return status == http.StatusOK
+	}
+	for _, code := range accepted {
+		if status == code {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultExtraProbeJitterFraction is the fraction of the polling
+// interval across which extra-target probes are spread when
+// Config.ExtraProbeJitterFraction is unset.
+const defaultExtraProbeJitterFraction = 0.5
+
+// extraProbeDelays returns a delay for each of count extra probe
+// targets, spreading the probes uniformly across the given fraction
+// of the polling interval so that they are not all dispatched at the
+// exact cycle start.
+func extraProbeDelays(count int, interval time.Duration, fraction float64) []time.Duration {
+	if count == 0 {
+		return nil
+	}
+	if fraction == 0 {
+		fraction = defaultExtraProbeJitterFraction
+	}
+	window := time.Duration(float64(interval) * fraction)
+	delays := make([]time.Duration, count)
+	for i := 0; i < count; i++ {
+		delays[i] = time.Duration(int64(window) * int64(i) / int64(count))
+	}
+	return delays
+}
+
+// ProbeTarget describes an additional endpoint that the canary polling
+// loop probes each cycle. Targets may terminate TLS differently, so
+// each carries its own TLS settings and expected body.
+type ProbeTarget struct {
+	// URL is the full http or https URL to probe.
+	URL string
+	// ExpectedBody is a string that the target's response body must
+	// contain. An empty value disables the body check.
+	ExpectedBody string
+	// CABundle is a PEM-encoded certificate bundle used to verify the
+	// target's serving certificate. When empty, the system roots are
+	// used. Only meaningful for https targets.
+	CABundle []byte
+	// InsecureSkipVerify disables verification of the target's serving
+	// certificate. Only meaningful for https targets.
+	InsecureSkipVerify bool
+}
+
+// dispatchExtraProbes schedules probe to be invoked for each target
+// after the corresponding delay.
+func dispatchExtraProbes(targets []ProbeTarget, delays []time.Duration, probe func(ProbeTarget)) {
+	for i, target := range targets {
+		target := target
+		time.AfterFunc(delays[i], func() {
+			probe(target)
+		})
+	}
+}
+
+// clientForProbeTarget returns an HTTP client for the given extra
+// probe target, layering the target's TLS settings on the shared probe
+// transport so that the cluster-proxy handling and the probe source
+// address apply to extra targets as well.
+func (r *reconciler) clientForProbeTarget(target ProbeTarget, host string) (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: target.InsecureSkipVerify,
+	}
+	if len(target.CABundle) != 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(target.CABundle) {
+			return nil, fmt.Errorf("failed to parse CA bundle for extra probe target %q", target.URL)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := r.newProbeTransport(tlsConfig)
+	// ProbeTargetIP pins connections to the canary route's endpoint
+	// and must not redirect extra targets; keep the source-address
+	// dialer but dial the target's own host.
+	if len(r.config.ProbeTargetIP) != 0 {
+		transport.DialContext = nil
+		if dialer := r.probeDialer(); dialer.LocalAddr != nil {
+			transport.DialContext = dialer.DialContext
+		}
+	}
+
+	options := r.probeOptionsForHost(host)
+	return &http.Client{
+		Timeout:   options.Timeout,
+		Transport: transport,
+	}, nil
+}
+
+// probeExtraTarget sends a single canary probe to the given target and
+// returns an error if the target is not serving the expected response.
+func (r *reconciler) probeExtraTarget(target ProbeTarget) error {
+	request, err := http.NewRequest("GET", target.URL, nil)
+	if err != nil {
+		return fmt.Errorf("error creating canary HTTP request for %q: %v", target.URL, err)
+	}
+
+	client, err := r.clientForProbeTarget(target, request.URL.Host)
+	if err != nil {
+		return err
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("error sending canary HTTP request to %q: %v", target.URL, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d from extra probe target %q", response.StatusCode, target.URL)
+	}
+
+	if len(target.ExpectedBody) != 0 {
+		bodyBytes, err := ioutil.ReadAll(response.Body)
+		if err != nil {
+			return fmt.Errorf("error reading response body from extra probe target %q: %v", target.URL, err)
+		}
+		if !strings.Contains(string(bodyBytes), target.ExpectedBody) {
+			return fmt.Errorf("expected response body from extra probe target %q to contain %q", target.URL, target.ExpectedBody)
+		}
+	}
+
+	return nil
+}
+
+// dialToTargetIP returns a dial function that uses the given dialer to
+// connect to the given IP regardless of the address's host name,
+// preserving the original port. The request's URL host is untouched, so
+// the Host header and TLS SNI still carry the route host.
+func dialToTargetIP(dialer *net.Dialer, targetIP string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		_, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, fmt.Errorf("error splitting address %q for canary probe target IP dial: %v", addr, err)
+		}
+		return dialer.DialContext(ctx, network, net.JoinHostPort(targetIP, port))
+	}
+}
+
+// newTraceparent returns a W3C traceparent header value with freshly
+// generated random trace and span IDs, along with the hex-encoded trace
+// ID for use as an exemplar label.
+func newTraceparent() (string, string, error) {
+	traceID := make([]byte, 16)
+	if _, err := rand.Read(traceID); err != nil {
+		return "", "", fmt.Errorf("error generating trace ID: %v", err)
+	}
+	spanID := make([]byte, 8)
+	if _, err := rand.Read(spanID); err != nil {
+		return "", "", fmt.Errorf("error generating span ID: %v", err)
+	}
+	traceIDHex := hex.EncodeToString(traceID)
+	return fmt.Sprintf("00-%s-%s-01", traceIDHex, hex.EncodeToString(spanID)), traceIDHex, nil
+}
+
+// checkDateHeader verifies that the given Date header value is present
+// and within maxSkew of now.
+func checkDateHeader(value string, now time.Time, maxSkew time.Duration) error {
+	if len(value) == 0 {
+		return errMissingDateHeader
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return fmt.Errorf("error parsing canary response Date header %q: %v", value, err)
+	}
+	skew := now.Sub(date)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > maxSkew {
+		return fmt.Errorf("%w: Date %q is %v from the operator's clock, allowed skew is %v", errDateSkewExceeded, value, skew, maxSkew)
+	}
+	return nil
+}
+
+// balanceAlgorithmAnnotation is the route annotation that specifies the
+// router's balance algorithm for the route.
+const balanceAlgorithmAnnotation = "haproxy.router.openshift.io/balance"
+
+// defaultBalanceProbeRequests is how many requests a balance probe
+// sends when Config.BalanceProbeRequests is unset.
+const defaultBalanceProbeRequests = 10
+
+// echoServerPodAckHeader is the response header in which the canary
+// echo server identifies the serving backend pod.
+const echoServerPodAckHeader = "x-request-pod"
+
+// probeBalanceAlgorithm sends several requests to the given route and
+// validates that the distribution of serving backends (observed via the
+// backend-identity response header) matches the balance algorithm that
+// the route is annotated with.
+func (r *reconciler) probeBalanceAlgorithm(route *routev1.Route) error {
+	algorithm := route.Annotations[balanceAlgorithmAnnotation]
+	if algorithm != "roundrobin" {
+		// Only roundrobin produces a distribution that can be
+		// validated deterministically.
+		return nil
+	}
+
+	requests := r.config.BalanceProbeRequests
+	if requests == 0 {
+		requests = defaultBalanceProbeRequests
 	}
 
+	options := r.probeOptionsForHost(route.Spec.Host)
+	client := r.newRouteProbeClient(route, options.Timeout)
+
+	counts := map[string]int{}
+	for i := 0; i < requests; i++ {
+		response, err := client.Get(canaryRouteURL(routeProbeScheme(route), route.Spec.Host))
+		if err != nil {
+			return fmt.Errorf("error sending canary balance probe request to %q: %v", route.Spec.Host, err)
+		}
+		backend := response.Header.Get(echoServerPodAckHeader)
+		response.Body.Close()
+		if len(backend) == 0 {
+			// The canary image does not echo a backend
+			// identity, so the distribution cannot be checked.
+			return nil
+		}
+		counts[backend]++
+	}
+
+	return checkBalanceDistribution(algorithm, counts)
+}
+
+// echoServerMethodAckHeader is the response header in which the canary
+// echo server echoes the request method it received.
+const echoServerMethodAckHeader = "x-request-method"
+
+// defaultMethodEchoProbeMethods is the method list a method echo probe
+// sends when Config.MethodEchoProbeMethods is unset.
+var defaultMethodEchoProbeMethods = []string{"POST", "PUT", "DELETE"}
+
+// probeMethodEcho sends a request per configured method through the
+// route and verifies that the backend received the same method,
+// catching router method-rewriting bugs. Methods the echo server does
+// not acknowledge are skipped.
+func (r *reconciler) probeMethodEcho(route *routev1.Route) error {
+	methods := r.config.MethodEchoProbeMethods
+	if len(methods) == 0 {
+		methods = defaultMethodEchoProbeMethods
+	}
+
+	options := r.probeOptionsForHost(route.Spec.Host)
+	client := r.newRouteProbeClient(route, options.Timeout)
+
+	for _, method := range methods {
+		request, err := http.NewRequest(method, canaryRouteURL(routeProbeScheme(route), route.Spec.Host), nil)
+		if err != nil {
+			return fmt.Errorf("error creating canary %s request: %v", method, err)
+		}
+		response, err := client.Do(request)
+		if err != nil {
+			return fmt.Errorf("error sending canary %s request to %q: %v", method, route.Spec.Host, err)
+		}
+		echoed := response.Header.Get(echoServerMethodAckHeader)
+		response.Body.Close()
+		if len(echoed) == 0 {
+			// The canary image does not echo the request method,
+			// so the round trip cannot be checked.
+			continue
+		}
+		if echoed != method {
+			return fmt.Errorf("canary request method %s arrived at the backend as %s", method, echoed)
+		}
+	}
+
+	return nil
+}
+
+// negativeProbeHost is the deliberately-unknown Host header that the
+// negative host probe sends. The router does not serve this host, so a
+// 200 response indicates that requests for unknown hosts are leaking to
+// a default backend.
+const negativeProbeHost = "canary-negative-check.invalid"
+
+// probeUnknownHost sends a request with a deliberately-unknown Host
+// header to the route's endpoint and verifies that the router rejects
+// it rather than leaking a default backend response.
+func (r *reconciler) probeUnknownHost(route *routev1.Route) error {
+	request, err := http.NewRequest("GET", canaryRouteURL(routeProbeScheme(route), route.Spec.Host), nil)
+	if err != nil {
+		return fmt.Errorf("error creating canary negative host probe request: %v", err)
+	}
+	// Connect to the route's endpoint but present a Host header that
+	// the router does not serve.
+	request.Host = negativeProbeHost
+
+	options := r.probeOptionsForHost(route.Spec.Host)
+	client := r.newRouteProbeClient(route, options.Timeout)
+
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("error sending canary negative host probe request to %q: %v", route.Spec.Host, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusOK {
+		return fmt.Errorf("expected router to reject unknown host %q, but got status code %d", negativeProbeHost, response.StatusCode)
+	}
+
+	return nil
+}
+
+// checkBalanceDistribution verifies that the observed per-backend
+// request counts are consistent with the given balance algorithm.
+func checkBalanceDistribution(algorithm string, counts map[string]int) error {
+	switch algorithm {
+	case "roundrobin":
+		// Roundrobin distributes requests evenly, so the
+		// most-used and least-used backends can differ by at
+		// most one request.
+		min, max := -1, 0
+		for _, count := range counts {
+			if min == -1 || count < min {
+				min = count
+			}
+			if count > max {
+				max = count
+			}
+		}
+		if max-min > 1 {
+			return fmt.Errorf("expected roundrobin to distribute requests evenly, but got distribution %v", counts)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,1770 @@
+package canary
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+
+	routev1 "github.com/openshift/api/route/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// echoServerFor returns an httptest TLS server that serves the given
+// number of redirect hops before responding with a healthy canary
+// response that echoes echoPort.
+func echoServerFor(redirectHops int, echoPort string) *httptest.Server {
+	mux := http.NewServeMux()
+	for i := 0; i < redirectHops; i++ {
+		hop := i
+		mux.HandleFunc(fmt.Sprintf("/hop-%d", hop), func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, fmt.Sprintf("/hop-%d", hop+1), http.StatusFound)
+		})
+	}
+	mux.HandleFunc(fmt.Sprintf("/hop-%d", redirectHops), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(echoServerPortAckHeader, echoPort)
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/hop-0", http.StatusFound)
+	})
+	return httptest.NewTLSServer(mux)
+}
+
+// routeForServer returns a canary route resource pointed at the
+// given test server with the given target port.
+func routeForServer(server *httptest.Server, port string) *routev1.Route {
+	return &routev1.Route{
+		Spec: routev1.RouteSpec{
+			Host: strings.TrimPrefix(server.URL, "https://"),
+			Port: &routev1.RoutePort{
+				TargetPort: intstr.FromString(port),
+			},
+			TLS: &routev1.TLSConfig{
+				Termination: routev1.TLSTerminationEdge,
+			},
+		},
+	}
+}
+
+func TestProbeRouteEndpointRedirectChain(t *testing.T) {
+	testCases := []struct {
+		description       string
+		redirectHops      int
+		maxProbeRedirects int
+		expectedChainLen  int
+		expectError       bool
+	}{
+		{
+			description:       "chain under the cap is recorded in full",
+			redirectHops:      1,
+			maxProbeRedirects: 3,
+			// the root handler redirects to hop-0, so one
+			// configured hop produces two recorded redirects.
+			expectedChainLen: 2,
+			expectError:      false,
+		},
+		{
+			description:       "chain over the cap is recorded up to the cap",
+			redirectHops:      5,
+			maxProbeRedirects: 3,
+			expectedChainLen:  3,
+			expectError:       true,
+		},
+	}
+
+	for _, tc := range testCases {
+		server := echoServerFor(tc.redirectHops, "8080")
+		defer server.Close()
+
+		r := &reconciler{
+			config: Config{
+				MaxProbeRedirects:   tc.maxProbeRedirects,
+				RecordRedirectChain: true,
+			},
+		}
+
+		route := routeForServer(server, "8080")
+		probeResult, err := r.probeRouteEndpoint(context.TODO(), route)
+		if tc.expectError && err == nil {
+			t.Errorf("%s: expected probeRouteEndpoint to return an error, but it did not", tc.description)
+		} else if !tc.expectError && err != nil {
+			t.Errorf("%s: probeRouteEndpoint returned an unexpected error: %v", tc.description, err)
+		}
+
+		if len(probeResult.RedirectChain) != tc.expectedChainLen {
+			t.Errorf("%s: expected redirect chain of length %d, but got %v", tc.description, tc.expectedChainLen, probeResult.RedirectChain)
+		}
+		for _, hop := range probeResult.RedirectChain {
+			if hop.StatusCode != http.StatusFound {
+				t.Errorf("%s: expected redirect hop status %d, but got %d", tc.description, http.StatusFound, hop.StatusCode)
+			}
+		}
+	}
+}
+
+func TestProbeRouteEndpointRedirectFailures(t *testing.T) {
+	// A redirect loop that never terminates, to exercise the redirect
+	// cap.
+	loopServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	}))
+	defer loopServer.Close()
+
+	// A terminal redirect: a 3xx status that the probe's client cannot
+	// follow because the response carries no Location header.
+	terminalServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(echoServerPortAckHeader, "8080")
+		w.WriteHeader(http.StatusFound)
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	defer terminalServer.Close()
+
+	testCases := []struct {
+		description   string
+		server        *httptest.Server
+		expectedError string
+	}{
+		{
+			description:   "redirect loop is cut off with a too many redirects error",
+			server:        loopServer,
+			expectedError: "too many redirects",
+		},
+		{
+			description:   "terminal 3xx response is reported as an unexpected redirect",
+			server:        terminalServer,
+			expectedError: "unexpected redirect status code 302",
+		},
+	}
+
+	for _, tc := range testCases {
+		r := &reconciler{
+			config: Config{
+				MaxProbeRedirects: 3,
+			},
+		}
+
+		route := routeForServer(tc.server, "8080")
+		_, err := r.probeRouteEndpoint(context.TODO(), route)
+		if err == nil {
+			t.Errorf("%s: expected probeRouteEndpoint to return an error, but it did not", tc.description)
+		} else if !strings.Contains(err.Error(), tc.expectedError) {
+			t.Errorf("%s: expected error to contain %q, but got %q", tc.description, tc.expectedError, err.Error())
+		}
+	}
+}
+
+func TestProbeRouteEndpointErrorClassification(t *testing.T) {
+	healthyHandler := func(status int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(echoServerPortAckHeader, "8080")
+			w.WriteHeader(status)
+			fmt.Fprint(w, CanaryHealthcheckResponse)
+		}
+	}
+
+	testCases := []struct {
+		description   string
+		handler       http.HandlerFunc
+		expectedError error
+	}{
+		{
+			description:   "unavailable route",
+			handler:       healthyHandler(http.StatusServiceUnavailable),
+			expectedError: ErrCanaryRouteUnavailable,
+		},
+		{
+			description:   "request timeout status",
+			handler:       healthyHandler(http.StatusRequestTimeout),
+			expectedError: ErrCanaryRequestTimeout,
+		},
+		{
+			description:   "server error status",
+			handler:       healthyHandler(http.StatusInternalServerError),
+			expectedError: ErrCanaryUnexpectedStatus,
+		},
+		{
+			description: "wrong port echo",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(echoServerPortAckHeader, "9999")
+				fmt.Fprint(w, CanaryHealthcheckResponse)
+			},
+			expectedError: ErrCanaryWrongPort,
+		},
+		{
+			description: "empty response body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(echoServerPortAckHeader, "8080")
+			},
+			expectedError: ErrCanaryBodyMismatch,
+		},
+		{
+			description: "unexpected response body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(echoServerPortAckHeader, "8080")
+				fmt.Fprint(w, "not the canary response")
+			},
+			expectedError: ErrCanaryBodyMismatch,
+		},
+	}
+
+	for _, tc := range testCases {
+		server := httptest.NewTLSServer(tc.handler)
+		defer server.Close()
+
+		r := &reconciler{}
+		route := routeForServer(server, "8080")
+		_, err := r.probeRouteEndpoint(context.TODO(), route)
+		if err == nil {
+			t.Errorf("%s: expected probeRouteEndpoint to return an error, but it did not", tc.description)
+		} else if !errors.Is(err, tc.expectedError) {
+			t.Errorf("%s: expected errors.Is to match %v, but got %v", tc.description, tc.expectedError, err)
+		}
+	}
+}
+
+func TestProbeRouteEndpointPortEchoHeader(t *testing.T) {
+	customHeader := "x-canary-port"
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(customHeader, "8080")
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	defer server.Close()
+
+	route := routeForServer(server, "8080")
+
+	// With the default header name the probe must fail, since the
+	// server echoes the port in a custom header.
+	r := &reconciler{}
+	if _, err := r.probeRouteEndpoint(context.TODO(), route); err == nil {
+		t.Error("expected probeRouteEndpoint to fail when the port echo header is missing, but it did not")
+	}
+
+	// With the custom header configured the probe succeeds.
+	r = &reconciler{
+		config: Config{
+			PortEchoHeader: customHeader,
+		},
+	}
+	if _, err := r.probeRouteEndpoint(context.TODO(), route); err != nil {
+		t.Errorf("probeRouteEndpoint returned an unexpected error with a custom port echo header: %v", err)
+	}
+}
+
+func TestProbeRouteEndpointUserAgent(t *testing.T) {
+	testCases := []struct {
+		description       string
+		userAgent         string
+		expectedUserAgent string
+	}{
+		{
+			description:       "default user agent",
+			userAgent:         "",
+			expectedUserAgent: defaultProbeUserAgent,
+		},
+		{
+			description:       "configured user agent",
+			userAgent:         "openshift-ingress-canary/4.8.0",
+			expectedUserAgent: "openshift-ingress-canary/4.8.0",
+		},
+	}
+
+	for _, tc := range testCases {
+		gotUserAgent := ""
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotUserAgent = r.Header.Get("User-Agent")
+			w.Header().Set(echoServerPortAckHeader, "8080")
+			fmt.Fprint(w, CanaryHealthcheckResponse)
+		}))
+		defer server.Close()
+
+		r := &reconciler{
+			config: Config{
+				UserAgent: tc.userAgent,
+			},
+		}
+
+		route := routeForServer(server, "8080")
+		if _, err := r.probeRouteEndpoint(context.TODO(), route); err != nil {
+			t.Errorf("%s: probeRouteEndpoint returned an unexpected error: %v", tc.description, err)
+		}
+		if gotUserAgent != tc.expectedUserAgent {
+			t.Errorf("%s: expected probe User-Agent %q, but got %q", tc.description, tc.expectedUserAgent, gotUserAgent)
+		}
+	}
+}
+
+func TestProbeRouteEndpointCompression(t *testing.T) {
+	testCases := []struct {
+		description string
+		corrupt     bool
+		expectError bool
+	}{
+		{
+			description: "valid gzip response body decompresses to the expected body",
+			corrupt:     false,
+			expectError: false,
+		},
+		{
+			description: "corrupt gzip response body fails the probe",
+			corrupt:     true,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		corrupt := tc.corrupt
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Accept-Encoding") != "gzip" {
+				t.Errorf("%s: expected probe request to send Accept-Encoding: gzip", tc.description)
+			}
+			w.Header().Set(echoServerPortAckHeader, "8080")
+			w.Header().Set("Content-Encoding", "gzip")
+			var buffer bytes.Buffer
+			gzipWriter := gzip.NewWriter(&buffer)
+			fmt.Fprint(gzipWriter, CanaryHealthcheckResponse)
+			gzipWriter.Close()
+			body := buffer.Bytes()
+			if corrupt {
+				// Truncating the gzip stream corrupts it.
+				body = body[:len(body)-4]
+			}
+			w.Write(body)
+		}))
+		defer server.Close()
+
+		r := &reconciler{
+			config: Config{
+				CompressionProbe: true,
+			},
+		}
+
+		route := routeForServer(server, "8080")
+		if _, err := r.probeRouteEndpoint(context.TODO(), route); tc.expectError && err == nil {
+			t.Errorf("%s: expected probeRouteEndpoint to return an error, but it did not", tc.description)
+		} else if !tc.expectError && err != nil {
+			t.Errorf("%s: probeRouteEndpoint returned an unexpected error: %v", tc.description, err)
+		}
+	}
+}
+
+func TestExtraProbeDelays(t *testing.T) {
+	testCases := []struct {
+		description string
+		count       int
+		interval    time.Duration
+		fraction    float64
+		expected    []time.Duration
+	}{
+		{
+			description: "no targets",
+			count:       0,
+			interval:    time.Minute,
+			fraction:    0.5,
+			expected:    nil,
+		},
+		{
+			description: "four targets spread across half the interval",
+			count:       4,
+			interval:    time.Minute,
+			fraction:    0.5,
+			expected:    []time.Duration{0, 7500 * time.Millisecond, 15 * time.Second, 22500 * time.Millisecond},
+		},
+		{
+			description: "zero fraction falls back to the default",
+			count:       2,
+			interval:    time.Minute,
+			fraction:    0,
+			expected:    []time.Duration{0, 15 * time.Second},
+		},
+	}
+
+	for _, tc := range testCases {
+		delays := extraProbeDelays(tc.count, tc.interval, tc.fraction)
+		if !cmp.Equal(delays, tc.expected) {
+			t.Errorf("%s: expected delays %v, but got %v", tc.description, tc.expected, delays)
+		}
+	}
+}
+
+func TestDispatchExtraProbes(t *testing.T) {
+	targets := []ProbeTarget{
+		{URL: "https://first.example.com"},
+		{URL: "https://second.example.com"},
+	}
+	delays := []time.Duration{0, 5 * time.Millisecond}
+
+	probed := make(chan string, len(targets))
+	dispatchExtraProbes(targets, delays, func(target ProbeTarget) {
+		probed <- target.URL
+	})
+
+	seen := map[string]bool{}
+	for range targets {
+		select {
+		case target := <-probed:
+			seen[target] = true
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for extra probes to be dispatched")
+		}
+	}
+
+	for _, target := range targets {
+		if !seen[target.URL] {
+			t.Errorf("expected target %q to be probed", target.URL)
+		}
+	}
+}
+
+// TestProbeExtraTargetTLS verifies that a mix of http and https extra
+// probe targets is probed with the correct per-target client.
+func TestProbeExtraTargetTLS(t *testing.T) {
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, "plain canary response")
+	}))
+	defer plain.Close()
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	defer secure.Close()
+	otherSecure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	defer otherSecure.Close()
+
+	secureCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: secure.Certificate().Raw})
+
+	// Build a CA that did not sign either server's certificate.
+	unrelatedKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate CA key: %v", err)
+	}
+	unrelatedTemplate := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "unrelated-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	unrelatedDER, err := x509.CreateCertificate(rand.Reader, &unrelatedTemplate, &unrelatedTemplate, &unrelatedKey.PublicKey, unrelatedKey)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %v", err)
+	}
+	unrelatedCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: unrelatedDER})
+
+	testCases := []struct {
+		description string
+		target      ProbeTarget
+		expectError bool
+	}{
+		{
+			description: "http target with expected body",
+			target:      ProbeTarget{URL: plain.URL, ExpectedBody: "plain canary response"},
+			expectError: false,
+		},
+		{
+			description: "http target with mismatched expected body",
+			target:      ProbeTarget{URL: plain.URL, ExpectedBody: "different body"},
+			expectError: true,
+		},
+		{
+			description: "https target with matching CA bundle",
+			target:      ProbeTarget{URL: secure.URL, CABundle: secureCA},
+			expectError: false,
+		},
+		{
+			description: "https target with the wrong CA bundle",
+			target:      ProbeTarget{URL: otherSecure.URL, CABundle: unrelatedCA},
+			expectError: true,
+		},
+		{
+			description: "https target with verification disabled",
+			target:      ProbeTarget{URL: otherSecure.URL, InsecureSkipVerify: true},
+			expectError: false,
+		},
+		{
+			description: "https target with an unparseable CA bundle",
+			target:      ProbeTarget{URL: secure.URL, CABundle: []byte("not a certificate")},
+			expectError: true,
+		},
+	}
+
+	r := &reconciler{}
+	for _, tc := range testCases {
+		err := r.probeExtraTarget(tc.target)
+		if tc.expectError && err == nil {
+			t.Errorf("%s: expected an error, but got none", tc.description)
+		} else if !tc.expectError && err != nil {
+			t.Errorf("%s: expected no error, but got: %v", tc.description, err)
+		}
+	}
+}
+
+func TestProbeRouteEndpointDateHeader(t *testing.T) {
+	testCases := []struct {
+		description string
+		date        func(http.Header)
+		expectError error
+	}{
+		{
+			description: "valid Date header passes",
+			date:        func(h http.Header) {},
+			expectError: nil,
+		},
+		{
+			description: "missing Date header fails",
+			date: func(h http.Header) {
+				// Suppress the Date header that the server
+				// would otherwise set automatically.
+				h["Date"] = nil
+			},
+			expectError: errMissingDateHeader,
+		},
+		{
+			description: "badly-skewed Date header fails",
+			date: func(h http.Header) {
+				h.Set("Date", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+			},
+			expectError: errDateSkewExceeded,
+		},
+	}
+
+	for _, tc := range testCases {
+		date := tc.date
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(echoServerPortAckHeader, "8080")
+			date(w.Header())
+			fmt.Fprint(w, CanaryHealthcheckResponse)
+		}))
+		defer server.Close()
+
+		r := &reconciler{
+			config: Config{
+				ValidateDateHeader: true,
+				MaxDateSkew:        5 * time.Minute,
+			},
+		}
+
+		route := routeForServer(server, "8080")
+		_, err := r.probeRouteEndpoint(context.TODO(), route)
+		if tc.expectError == nil {
+			if err != nil {
+				t.Errorf("%s: probeRouteEndpoint returned an unexpected error: %v", tc.description, err)
+			}
+		} else if !errors.Is(err, tc.expectError) {
+			t.Errorf("%s: expected error %q, but got %v", tc.description, tc.expectError, err)
+		}
+	}
+}
+
+func TestProbeOptionsForHost(t *testing.T) {
+	r := &reconciler{
+		config: Config{
+			HostProbeOverrides: map[string]ProbeOptions{
+				"slow.example.com": {
+					Timeout: 30 * time.Second,
+				},
+				"custom-body.example.com": {
+					ExpectedBody: "custom canary body",
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		description  string
+		host         string
+		expectedOpts ProbeOptions
+	}{
+		{
+			description: "host with a timeout override keeps the default body",
+			host:        "slow.example.com",
+			expectedOpts: ProbeOptions{
+				Timeout:      30 * time.Second,
+				ExpectedBody: CanaryHealthcheckResponse,
+			},
+		},
+		{
+			description: "host with a body override keeps the default timeout",
+			host:        "custom-body.example.com",
+			expectedOpts: ProbeOptions{
+				Timeout:      defaultProbeTimeout,
+				ExpectedBody: "custom canary body",
+			},
+		},
+		{
+			description: "host without an override uses the defaults",
+			host:        "other.example.com",
+			expectedOpts: ProbeOptions{
+				Timeout:      defaultProbeTimeout,
+				ExpectedBody: CanaryHealthcheckResponse,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		if options := r.probeOptionsForHost(tc.host); !cmp.Equal(options, tc.expectedOpts) {
+			t.Errorf("%s: expected probe options %v, but got %v", tc.description, tc.expectedOpts, options)
+		}
+	}
+
+	// A configured global probe timeout applies to hosts without a
+	// per-host override, while per-host overrides take precedence.
+	r.config.ProbeTimeout = 2 * time.Second
+	if options := r.probeOptionsForHost("other.example.com"); options.Timeout != 2*time.Second {
+		t.Errorf("expected configured probe timeout %v, but got %v", 2*time.Second, options.Timeout)
+	}
+	if options := r.probeOptionsForHost("slow.example.com"); options.Timeout != 30*time.Second {
+		t.Errorf("expected per-host timeout override %v, but got %v", 30*time.Second, options.Timeout)
+	}
+
+	// A configured global expected body applies the same way.
+	r.config.ExpectedBody = "custom image body"
+	if options := r.probeOptionsForHost("other.example.com"); options.ExpectedBody != "custom image body" {
+		t.Errorf("expected configured expected body %q, but got %q", "custom image body", options.ExpectedBody)
+	}
+	if options := r.probeOptionsForHost("custom-body.example.com"); options.ExpectedBody != "custom canary body" {
+		t.Errorf("expected per-host expected body override %q, but got %q", "custom canary body", options.ExpectedBody)
+	}
+}
+
+func TestProbeRouteEndpointHostTimeoutOverride(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Header().Set(echoServerPortAckHeader, "8080")
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	defer server.Close()
+
+	route := routeForServer(server, "8080")
+	r := &reconciler{
+		config: Config{
+			HostProbeOverrides: map[string]ProbeOptions{
+				route.Spec.Host: {
+					Timeout: 50 * time.Millisecond,
+				},
+			},
+		},
+	}
+
+	if _, err := r.probeRouteEndpoint(context.TODO(), route); err == nil {
+		t.Error("expected probeRouteEndpoint to time out with the per-host timeout override, but it did not")
+	}
+}
+
+func TestProbeRouteEndpointTargetIP(t *testing.T) {
+	hosts := make(chan string, 1)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		hosts <- req.Host
+		w.Header().Set(echoServerPortAckHeader, "8080")
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	defer server.Close()
+
+	targetIP, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "https://"))
+	if err != nil {
+		t.Fatalf("failed to split test server address: %v", err)
+	}
+
+	r := &reconciler{
+		config: Config{
+			ProbeTargetIP: targetIP,
+		},
+	}
+
+	// The route host does not resolve; the probe must connect to the
+	// configured target IP while preserving the host.
+	routeHost := "canary-target-ip-test.invalid:" + port
+	route := &routev1.Route{
+		Spec: routev1.RouteSpec{
+			Host: routeHost,
+			Port: &routev1.RoutePort{
+				TargetPort: intstr.FromString("8080"),
+			},
+			TLS: &routev1.TLSConfig{
+				Termination: routev1.TLSTerminationEdge,
+			},
+		},
+	}
+
+	if _, err := r.probeRouteEndpoint(context.TODO(), route); err != nil {
+		t.Fatalf("probeRouteEndpoint returned an unexpected error: %v", err)
+	}
+
+	if host := <-hosts; host != routeHost {
+		t.Errorf("expected probe request to carry host %q, but got %q", routeHost, host)
+	}
+}
+
+func TestProbeRouteEndpointBackendAddr(t *testing.T) {
+	server := echoServerFor(0, "8080")
+	defer server.Close()
+
+	r := &reconciler{
+		config: Config{},
+	}
+
+	route := routeForServer(server, "8080")
+	probeResult, err := r.probeRouteEndpoint(context.TODO(), route)
+	if err != nil {
+		t.Fatalf("probeRouteEndpoint returned an unexpected error: %v", err)
+	}
+
+	expectedAddr := server.Listener.Addr().String()
+	if probeResult.BackendAddr != expectedAddr {
+		t.Errorf("expected probe result backend address %q, but got %q", expectedAddr, probeResult.BackendAddr)
+	}
+}
+
+func TestProbeRouteEndpointTraceExemplar(t *testing.T) {
+	traceparents := make(chan string, 1)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		traceparents <- req.Header.Get("traceparent")
+		w.Header().Set(echoServerPortAckHeader, "8080")
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	defer server.Close()
+
+	r := &reconciler{
+		config: Config{
+			TraceExemplars: true,
+		},
+	}
+
+	route := routeForServer(server, "8080")
+	if _, err := r.probeRouteEndpoint(context.TODO(), route); err != nil {
+		t.Fatalf("probeRouteEndpoint returned an unexpected error: %v", err)
+	}
+
+	traceparent := <-traceparents
+	parts := strings.Split(traceparent, "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		t.Fatalf("expected probe to send a W3C traceparent header, but got %q", traceparent)
+	}
+	traceID := parts[1]
+
+	metric := &dto.Metric{}
+	if err := CanaryRequestTime.WithLabelValues(route.Spec.Host).(prometheus.Metric).Write(metric); err != nil {
+		t.Fatalf("failed to read canary request time metric: %v", err)
+	}
+	found := false
+	for _, bucket := range metric.GetHistogram().GetBucket() {
+		exemplar := bucket.GetExemplar()
+		if exemplar == nil {
+			continue
+		}
+		for _, label := range exemplar.GetLabel() {
+			if label.GetName() == "trace_id" && label.GetValue() == traceID {
+				found = true
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected an exemplar with trace_id %q on the canary request time histogram", traceID)
+	}
+}
+
+func TestProbeMethodEcho(t *testing.T) {
+	testCases := []struct {
+		description string
+		// rewrite simulates a router bug that rewrites every
+		// request method to GET.
+		rewrite     bool
+		expectError bool
+	}{
+		{
+			description: "methods round-trip unchanged",
+			rewrite:     false,
+			expectError: false,
+		},
+		{
+			description: "rewritten method fails the probe",
+			rewrite:     true,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		rewrite := tc.rewrite
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			method := req.Method
+			if rewrite {
+				method = "GET"
+			}
+			w.Header().Set(echoServerMethodAckHeader, method)
+			fmt.Fprint(w, CanaryHealthcheckResponse)
+		}))
+		defer server.Close()
+
+		r := &reconciler{
+			config: Config{
+				MethodEchoProbe:        true,
+				MethodEchoProbeMethods: []string{"POST", "DELETE"},
+			},
+		}
+
+		route := routeForServer(server, "8080")
+		if err := r.probeMethodEcho(route); tc.expectError && err == nil {
+			t.Errorf("%s: expected probeMethodEcho to return an error, but it did not", tc.description)
+		} else if !tc.expectError && err != nil {
+			t.Errorf("%s: probeMethodEcho returned an unexpected error: %v", tc.description, err)
+		}
+	}
+}
+
+func TestProbeUnknownHost(t *testing.T) {
+	testCases := []struct {
+		description string
+		// defaultBackend simulates a router that serves a default
+		// backend for unknown hosts instead of rejecting them.
+		defaultBackend bool
+		expectError    bool
+	}{
+		{
+			description:    "unknown host is rejected",
+			defaultBackend: false,
+			expectError:    false,
+		},
+		{
+			description:    "unknown host leaks a default backend",
+			defaultBackend: true,
+			expectError:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		defaultBackend := tc.defaultBackend
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Host != negativeProbeHost {
+				t.Errorf("%s: expected negative probe to send host %q, but got %q", tc.description, negativeProbeHost, req.Host)
+			}
+			if defaultBackend {
+				fmt.Fprint(w, "default backend")
+				return
+			}
+			http.Error(w, "no route for host", http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		r := &reconciler{
+			config: Config{
+				NegativeHostProbe: true,
+			},
+		}
+
+		route := routeForServer(server, "8080")
+		if err := r.probeUnknownHost(route); tc.expectError && err == nil {
+			t.Errorf("%s: expected probeUnknownHost to return an error, but it did not", tc.description)
+		} else if !tc.expectError && err != nil {
+			t.Errorf("%s: probeUnknownHost returned an unexpected error: %v", tc.description, err)
+		}
+	}
+}
+
+func TestProbeBalanceAlgorithm(t *testing.T) {
+	testCases := []struct {
+		description string
+		backends    []string
+		expectError bool
+	}{
+		{
+			description: "even roundrobin distribution passes",
+			backends:    []string{"pod-a", "pod-b", "pod-c"},
+			expectError: false,
+		},
+		{
+			description: "skewed distribution fails",
+			backends:    []string{"pod-a", "pod-a", "pod-a", "pod-a", "pod-b"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		backends := tc.backends
+		requests := 0
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set(echoServerPodAckHeader, backends[requests%len(backends)])
+			requests++
+			fmt.Fprint(w, CanaryHealthcheckResponse)
+		}))
+		defer server.Close()
+
+		r := &reconciler{
+			config: Config{
+				BalanceProbe:         true,
+				BalanceProbeRequests: 2 * len(tc.backends),
+			},
+		}
+
+		route := routeForServer(server, "8080")
+		route.Annotations = map[string]string{
+			balanceAlgorithmAnnotation: "roundrobin",
+		}
+
+		if err := r.probeBalanceAlgorithm(route); tc.expectError && err == nil {
+			t.Errorf("%s: expected probeBalanceAlgorithm to return an error, but it did not", tc.description)
+		} else if !tc.expectError && err != nil {
+			t.Errorf("%s: probeBalanceAlgorithm returned an unexpected error: %v", tc.description, err)
+		}
+	}
+}
+
+// TestProbeRouteEndpointScheme verifies that routes with no TLS
+// termination are probed over plain http while TLS-terminated routes
+// are probed over https.
+func TestCanaryRouteURL(t *testing.T) {
+	testCases := []struct {
+		description string
+		scheme      string
+		host        string
+		expected    string
+	}{
+		{
+			description: "DNS host",
+			scheme:      "https",
+			host:        "canary-openshift-ingress-canary.apps.example.com",
+			expected:    "https://canary-openshift-ingress-canary.apps.example.com",
+		},
+		{
+			description: "host with an explicit port",
+			scheme:      "https",
+			host:        "canary.example.com:8443",
+			expected:    "https://canary.example.com:8443",
+		},
+		{
+			description: "IPv6 literal is bracket wrapped",
+			scheme:      "http",
+			host:        "fd00::1234",
+			expected:    "http://[fd00::1234]",
+		},
+		{
+			description: "bracketed IPv6 literal with a port is preserved",
+			scheme:      "https",
+			host:        "[fd00::1234]:8443",
+			expected:    "https://[fd00::1234]:8443",
+		},
+		{
+			description: "IPv4 literal",
+			scheme:      "http",
+			host:        "10.0.0.1",
+			expected:    "http://10.0.0.1",
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := canaryRouteURL(tc.scheme, tc.host); got != tc.expected {
+			t.Errorf("%s: expected canary route URL %q, but got %q", tc.description, tc.expected, got)
+		}
+	}
+}
+
+func TestProbeRouteEndpointScheme(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set(echoServerPortAckHeader, "8080")
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	defer server.Close()
+
+	route := &routev1.Route{
+		Spec: routev1.RouteSpec{
+			Host: strings.TrimPrefix(server.URL, "http://"),
+			Port: &routev1.RoutePort{
+				TargetPort: intstr.FromString("8080"),
+			},
+		},
+	}
+
+	r := &reconciler{}
+	if _, err := r.probeRouteEndpoint(context.TODO(), route); err != nil {
+		t.Errorf("expected http probe of a route without TLS termination to succeed, but got: %v", err)
+	}
+}
+
+// TestProbeRouteEndpointTLSHandshakeError verifies that a failure to
+// establish the TLS connection is surfaced as a distinct error.
+func TestProbeRouteEndpointTLSHandshakeError(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set(echoServerPortAckHeader, "8080")
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	defer server.Close()
+
+	// A CA bundle that did not sign the server's certificate forces a
+	// verification failure during the handshake.
+	unrelatedKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate CA key: %v", err)
+	}
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "unrelated-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &unrelatedKey.PublicKey, unrelatedKey)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %v", err)
+	}
+
+	r := &reconciler{
+		config: Config{
+			ProbeRouterCABundle: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		},
+	}
+
+	route := routeForServer(server, "8080")
+	_, err = r.probeRouteEndpoint(context.TODO(), route)
+	if err == nil {
+		t.Fatal("expected probeRouteEndpoint to fail the TLS handshake, but got no error")
+	}
+	if !strings.Contains(err.Error(), "error establishing TLS connection") {
+		t.Errorf("expected a distinct TLS handshake error, but got: %v", err)
+	}
+}
+
+// TestProbeRouteEndpointExpectedBody verifies that a configured
+// expected body is honored and that an empty response body is still a
+// failure regardless of the configured string.
+func TestProbeRouteEndpointExpectedBody(t *testing.T) {
+	body := "custom image body"
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set(echoServerPortAckHeader, "8080")
+		fmt.Fprint(w, body)
+	}))
+	defer server.Close()
+
+	r := &reconciler{
+		config: Config{
+			ExpectedBody: "custom image body",
+		},
+	}
+
+	route := routeForServer(server, "8080")
+	if _, err := r.probeRouteEndpoint(context.TODO(), route); err != nil {
+		t.Errorf("expected probe with a custom expected body to succeed, but got: %v", err)
+	}
+
+	// An empty response body fails even though it trivially
+	// "contains" an empty expected string.
+	body = ""
+	r.config.ExpectedBody = ""
+	if _, err := r.probeRouteEndpoint(context.TODO(), route); err == nil {
+		t.Error("expected probe with an empty response body to fail, but it did not")
+	}
+}
+
+// TestProbeRouteEndpointContextCancel verifies that canceling the
+// probe's parent context aborts an in-flight probe promptly rather
+// than waiting out the probe timeout.
+func TestProbeRouteEndpointContextCancel(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Hold the request open until the client gives up.
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	r := &reconciler{}
+	route := routeForServer(server, "8080")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	_, err := r.probeRouteEndpoint(ctx, route)
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("expected probeRouteEndpoint to return an error after context cancellation, but it did not")
+	}
+	if !strings.Contains(err.Error(), context.Canceled.Error()) {
+		t.Errorf("expected error to mention %q, but got %q", context.Canceled.Error(), err.Error())
+	}
+	if elapsed >= defaultProbeTimeout {
+		t.Errorf("expected probeRouteEndpoint to return promptly after context cancellation, but it took %v", elapsed)
+	}
+}
+
+// TestProbeTLSConfig verifies that a supplied CA bundle is loaded into
+// the probe TLS configuration's RootCAs pool, that a ConfigMap-sourced
+// bundle works the same way, and that the explicit skip-verify escape
+// hatch wins over any bundle.
+func TestProbeTLSConfig(t *testing.T) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate CA key: %v", err)
+	}
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "probe-test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %v", err)
+	}
+	bundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	systemPool, err := x509.SystemCertPool()
+	if err != nil {
+		t.Fatalf("failed to load the system cert pool: %v", err)
+	}
+	systemSubjects := len(systemPool.Subjects())
+
+	caConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: controller.GlobalUserSpecifiedConfigNamespace,
+			Name:      "canary-probe-ca",
+		},
+		Data: map[string]string{
+			"ca-bundle.crt": string(bundle),
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(operatorclient.GetScheme()).WithObjects(caConfigMap).Build()
+
+	testCases := []struct {
+		description      string
+		reconciler       *reconciler
+		expectSkipVerify bool
+	}{
+		{
+			description: "if no CA bundle is configured",
+			reconciler:  &reconciler{},
+			// The default router certificate may be self signed, so
+			// verification is skipped by default.
+			expectSkipVerify: true,
+		},
+		{
+			description: "if a PEM CA bundle is configured",
+			reconciler: &reconciler{
+				config: Config{
+					ProbeRouterCABundle: bundle,
+				},
+			},
+			expectSkipVerify: false,
+		},
+		{
+			description: "if a CA bundle configmap is configured",
+			reconciler: &reconciler{
+				client: fakeClient,
+				config: Config{
+					ProbeRouterCABundleConfigMap: caConfigMap.Name,
+				},
+			},
+			expectSkipVerify: false,
+		},
+		{
+			description: "if skipping verification is explicitly configured",
+			reconciler: &reconciler{
+				config: Config{
+					ProbeRouterCABundle:     bundle,
+					ProbeInsecureSkipVerify: true,
+				},
+			},
+			expectSkipVerify: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tlsConfig := tc.reconciler.probeTLSConfig()
+		if tlsConfig.InsecureSkipVerify != tc.expectSkipVerify {
+			t.Errorf("%s: expected InsecureSkipVerify to be %t, but got %t", tc.description, tc.expectSkipVerify, tlsConfig.InsecureSkipVerify)
+			continue
+		}
+		if tc.expectSkipVerify {
+			continue
+		}
+		if tlsConfig.RootCAs == nil {
+			t.Errorf("%s: expected the TLS configuration to have a RootCAs pool, but it was nil", tc.description)
+			continue
+		}
+		// The pool is the system roots extended with the bundle's CA.
+		if subjects := len(tlsConfig.RootCAs.Subjects()); subjects != systemSubjects+1 {
+			t.Errorf("%s: expected the RootCAs pool to hold the system roots plus the supplied CA (%d subjects), but got %d", tc.description, systemSubjects+1, subjects)
+		}
+	}
+}
+
+// TestProbeTLSConfigForRoute verifies that the probe TLS configuration
+// carries the route host as the TLS ServerName (SNI), with any port
+// suffix stripped.
+func TestProbeTLSConfigForRoute(t *testing.T) {
+	testCases := []struct {
+		description        string
+		host               string
+		expectedServerName string
+	}{
+		{
+			description:        "if the route host is a plain DNS name",
+			host:               "canary-openshift-ingress-canary.apps.example.com",
+			expectedServerName: "canary-openshift-ingress-canary.apps.example.com",
+		},
+		{
+			description:        "if the route host carries a port",
+			host:               "canary.apps.example.com:8443",
+			expectedServerName: "canary.apps.example.com",
+		},
+	}
+
+	for _, tc := range testCases {
+		r := &reconciler{}
+		route := &routev1.Route{
+			Spec: routev1.RouteSpec{
+				Host: tc.host,
+			},
+		}
+		tlsConfig := r.probeTLSConfigForRoute(route)
+		if tlsConfig.ServerName != tc.expectedServerName {
+			t.Errorf("%s: expected ServerName %q, but got %q", tc.description, tc.expectedServerName, tlsConfig.ServerName)
+		}
+	}
+}
+
+// TestProbeRouteEndpointMethodAndPath verifies that the configured
+// probe method, path, and body reach the canary backend, and that the
+// defaults remain a bare GET of "/".
+func TestProbeRouteEndpointMethodAndPath(t *testing.T) {
+	testCases := []struct {
+		description    string
+		config         Config
+		expectedMethod string
+		expectedPath   string
+		expectedBody   string
+	}{
+		{
+			description:    "if no method or path is configured",
+			config:         Config{},
+			expectedMethod: http.MethodGet,
+			expectedPath:   "/",
+		},
+		{
+			description: "if a custom method, path, and body are configured",
+			config: Config{
+				ProbeMethod: http.MethodPost,
+				ProbePath:   "/healthz",
+				ProbeBody:   "ping",
+			},
+			expectedMethod: http.MethodPost,
+			expectedPath:   "/healthz",
+			expectedBody:   "ping",
+		},
+		{
+			description: "if the configured path lacks a leading slash",
+			config: Config{
+				ProbePath: "healthz",
+			},
+			expectedMethod: http.MethodGet,
+			expectedPath:   "/healthz",
+		},
+	}
+
+	for _, tc := range testCases {
+		var gotMethod, gotPath, gotBody string
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			gotMethod = req.Method
+			gotPath = req.URL.Path
+			body, _ := ioutil.ReadAll(req.Body)
+			gotBody = string(body)
+			w.Header().Set(echoServerPortAckHeader, "8080")
+			fmt.Fprint(w, CanaryHealthcheckResponse)
+		}))
+
+		r := &reconciler{
+			config: tc.config,
+		}
+		route := routeForServer(server, "8080")
+		if _, err := r.probeRouteEndpoint(context.TODO(), route); err != nil {
+			t.Errorf("%s: probeRouteEndpoint returned an unexpected error: %v", tc.description, err)
+		}
+		server.Close()
+
+		if gotMethod != tc.expectedMethod {
+			t.Errorf("%s: expected probe method %q, but got %q", tc.description, tc.expectedMethod, gotMethod)
+		}
+		if gotPath != tc.expectedPath {
+			t.Errorf("%s: expected probe path %q, but got %q", tc.description, tc.expectedPath, gotPath)
+		}
+		if gotBody != tc.expectedBody {
+			t.Errorf("%s: expected probe body %q, but got %q", tc.description, tc.expectedBody, gotBody)
+		}
+	}
+}
+
+// TestProbeRouteEndpointProbePathAnnotation verifies that a per-route
+// probe path annotation is honored, winning over the configured path,
+// and that the body and port-echo checks run against the annotated
+// path's response.
+func TestProbeRouteEndpointProbePathAnnotation(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/custom-healthz", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set(echoServerPortAckHeader, "8080")
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	r := &reconciler{
+		config: Config{
+			ProbePath: "/wrong-path",
+		},
+	}
+
+	route := routeForServer(server, "8080")
+	route.Annotations = map[string]string{
+		CanaryProbePathAnnotation: "/custom-healthz",
+	}
+	if _, err := r.probeRouteEndpoint(context.TODO(), route); err != nil {
+		t.Errorf("expected probe of the annotated path to succeed, but got: %v", err)
+	}
+
+	// Without the annotation, the configured path misses the health
+	// handler and the probe fails.
+	route.Annotations = nil
+	if _, err := r.probeRouteEndpoint(context.TODO(), route); err == nil {
+		t.Error("expected probe of the configured wrong path to fail, but it did not")
+	}
+}
+
+// TestProbeRouteEndpointDNSFailure verifies that a probe of a
+// non-resolvable host is classified as a DNS resolution failure and
+// increments the dedicated DNS failure counter.
+func TestProbeRouteEndpointDNSFailure(t *testing.T) {
+	r := &reconciler{}
+
+	// Hosts under .invalid never resolve (RFC 6761).
+	route := &routev1.Route{
+		Spec: routev1.RouteSpec{
+			Host: "canary-dns-failure-test.invalid",
+			Port: &routev1.RoutePort{
+				TargetPort: intstr.FromInt(8080),
+			},
+		},
+	}
+
+	before := testutil.ToFloat64(CanaryDNSFailures)
+	_, err := r.probeRouteEndpoint(context.TODO(), route)
+	if err == nil {
+		t.Fatal("expected probeRouteEndpoint to return an error for a non-resolvable host, but it did not")
+	}
+	if !isDNSResolutionError(err) {
+		t.Fatalf("expected a DNS resolution error for a non-resolvable host, but got %v", err)
+	}
+	if after := testutil.ToFloat64(CanaryDNSFailures); after != before+1 {
+		t.Errorf("expected the DNS failure counter to increment from %v to %v, but got %v", before, before+1, after)
+	}
+}
+
+// TestProbeRouteEndpointLatency verifies that a successful probe
+// populates the result's measured latency and that the latency SLO
+// check fires only above the configured threshold.
+func TestProbeRouteEndpointLatency(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(echoServerPortAckHeader, "8080")
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	defer server.Close()
+
+	r := &reconciler{}
+	route := routeForServer(server, "8080")
+	probeResult, err := r.probeRouteEndpoint(context.TODO(), route)
+	if err != nil {
+		t.Fatalf("probeRouteEndpoint returned an unexpected error: %v", err)
+	}
+	if probeResult.Latency <= 0 {
+		t.Errorf("expected a successful probe to populate a positive latency, but got %v", probeResult.Latency)
+	}
+
+	testCases := []struct {
+		description string
+		maxLatency  time.Duration
+		latency     time.Duration
+		expect      bool
+	}{
+		{
+			description: "no SLO configured",
+			maxLatency:  0,
+			latency:     time.Hour,
+			expect:      false,
+		},
+		{
+			description: "latency under the SLO",
+			maxLatency:  time.Second,
+			latency:     500 * time.Millisecond,
+			expect:      false,
+		},
+		{
+			description: "latency over the SLO",
+			maxLatency:  time.Second,
+			latency:     2 * time.Second,
+			expect:      true,
+		},
+	}
+	for _, tc := range testCases {
+		r := &reconciler{
+			config: Config{
+				MaxLatency: tc.maxLatency,
+			},
+		}
+		if got := r.latencyExceedsSLO(tc.latency); got != tc.expect {
+			t.Errorf("%s: expected latencyExceedsSLO to be %t, but got %t", tc.description, tc.expect, got)
+		}
+	}
+}
+
+// TestProbeRouteEndpointNilPort verifies that probing a route that has
+// no Spec.Port fails cleanly and registers the misconfiguration in
+// metrics instead of panicking.
+func TestProbeRouteEndpointNilPort(t *testing.T) {
+	r := &reconciler{}
+
+	route := &routev1.Route{
+		Spec: routev1.RouteSpec{
+			Host: "canary-nil-port-test.example.com",
+		},
+	}
+
+	before := testutil.ToFloat64(CanaryRouteMisconfigured)
+	_, err := r.probeRouteEndpoint(context.TODO(), route)
+	if err == nil {
+		t.Fatal("expected probeRouteEndpoint to return an error for a route without Spec.Port, but it did not")
+	}
+	if !strings.Contains(err.Error(), "has no Spec.Port") {
+		t.Fatalf("expected the error to mention the missing Spec.Port, but got %v", err)
+	}
+	if after := testutil.ToFloat64(CanaryRouteMisconfigured); after != before+1 {
+		t.Errorf("expected the misconfigured route counter to increment from %v to %v, but got %v", before, before+1, after)
+	}
+}
+
+// TestProbeDialerSourceAddress verifies that a configured probe source
+// address is bound on the probe dialer and wired into the transport,
+// and that the OS default is preserved when the address is unset or
+// invalid.
+func TestProbeDialerSourceAddress(t *testing.T) {
+	testCases := []struct {
+		description   string
+		sourceAddress string
+		expectedIP    string
+	}{
+		{
+			description:   "no source address configured",
+			sourceAddress: "",
+		},
+		{
+			description:   "valid source address",
+			sourceAddress: "127.0.0.1",
+			expectedIP:    "127.0.0.1",
+		},
+		{
+			description:   "invalid source address falls back to the OS default",
+			sourceAddress: "not-an-ip",
+		},
+	}
+
+	for _, tc := range testCases {
+		r := &reconciler{
+			config: Config{
+				ProbeSourceAddress: tc.sourceAddress,
+			},
+		}
+		dialer := r.probeDialer()
+		if len(tc.expectedIP) == 0 {
+			if dialer.LocalAddr != nil {
+				t.Errorf("%s: expected the dialer to have no local address, but got %v", tc.description, dialer.LocalAddr)
+			}
+			continue
+		}
+		tcpAddr, ok := dialer.LocalAddr.(*net.TCPAddr)
+		if !ok {
+			t.Errorf("%s: expected the dialer local address to be a TCP address, but got %T", tc.description, dialer.LocalAddr)
+		} else if tcpAddr.IP.String() != tc.expectedIP {
+			t.Errorf("%s: expected the dialer local address %q, but got %q", tc.description, tc.expectedIP, tcpAddr.IP.String())
+		}
+		if transport := r.newProbeTransport(nil); transport.DialContext == nil {
+			t.Errorf("%s: expected the transport to use the source-bound dialer, but DialContext is unset", tc.description)
+		}
+	}
+
+	// A probe bound to the loopback source address must still reach a
+	// loopback test server.
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(echoServerPortAckHeader, "8080")
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	defer server.Close()
+
+	r := &reconciler{
+		config: Config{
+			ProbeSourceAddress: "127.0.0.1",
+		},
+	}
+	if _, err := r.probeRouteEndpoint(context.TODO(), routeForServer(server, "8080")); err != nil {
+		t.Errorf("probeRouteEndpoint with a loopback source address returned an unexpected error: %v", err)
+	}
+}
+
+// capturingLogger is a logr.Logger that records every Info call's
+// message and key/value pairs for assertions.
+type capturingLogger struct {
+	messages      []string
+	keysAndValues [][]interface{}
+}
+
+func (l *capturingLogger) Enabled() bool { return true }
+func (l *capturingLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.messages = append(l.messages, msg)
+	l.keysAndValues = append(l.keysAndValues, keysAndValues)
+}
+func (l *capturingLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	l.Info(msg, keysAndValues...)
+}
+func (l *capturingLogger) V(level int) logr.Logger                             { return l }
+func (l *capturingLogger) WithValues(keysAndValues ...interface{}) logr.Logger { return l }
+func (l *capturingLogger) WithName(name string) logr.Logger                    { return l }
+
+// TestVerboseProbeDiagnostics verifies that a verbose probe logs the
+// per-phase timing breakdown and that the normal path stays quiet.
+func TestVerboseProbeDiagnostics(t *testing.T) {
+	sink := &capturingLogger{}
+	originalLog := log
+	log = sink
+	defer func() { log = originalLog }()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(echoServerPortAckHeader, "8080")
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	defer server.Close()
+
+	r := &reconciler{
+		config: Config{
+			Verbose: true,
+		},
+	}
+	if _, err := r.probeRouteEndpoint(context.TODO(), routeForServer(server, "8080")); err != nil {
+		t.Fatalf("probeRouteEndpoint returned an unexpected error: %v", err)
+	}
+
+	expectedFields := []string{"host", "dnsLookup", "tcpConnection", "tlsHandshake", "serverProcessing", "contentTransfer", "total", "status", "bodyLength"}
+	found := false
+	for i, msg := range sink.messages {
+		if msg != "canary probe diagnostics" {
+			continue
+		}
+		found = true
+		fields := map[string]bool{}
+		kv := sink.keysAndValues[i]
+		for j := 0; j+1 < len(kv); j += 2 {
+			if key, ok := kv[j].(string); ok {
+				fields[key] = true
+			}
+		}
+		for _, field := range expectedFields {
+			if !fields[field] {
+				t.Errorf("expected verbose probe diagnostics to include field %q, but it is missing", field)
+			}
+		}
+	}
+	if !found {
+		t.Error("expected a verbose probe diagnostics log entry, but none was produced")
+	}
+
+	// Without verbose mode, the same probe must not produce
+	// diagnostics.
+	sink.messages, sink.keysAndValues = nil, nil
+	r = &reconciler{}
+	if _, err := r.probeRouteEndpoint(context.TODO(), routeForServer(server, "8080")); err != nil {
+		t.Fatalf("probeRouteEndpoint returned an unexpected error: %v", err)
+	}
+	for _, msg := range sink.messages {
+		if msg == "canary probe diagnostics" {
+			t.Error("expected no verbose probe diagnostics without Config.Verbose, but an entry was produced")
+		}
+	}
+}
+
+// TestProbeRouteEndpointAcceptableStatusCodes verifies that a probe
+// succeeds for any configured acceptable status code and keeps
+// rejecting codes outside the set.
+func TestProbeRouteEndpointAcceptableStatusCodes(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(echoServerPortAckHeader, "8080")
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		description   string
+		acceptedCodes []int
+		expectError   bool
+	}{
+		{
+			description:   "202 is rejected by default",
+			acceptedCodes: nil,
+			expectError:   true,
+		},
+		{
+			description:   "202 is accepted when configured",
+			acceptedCodes: []int{http.StatusOK, http.StatusAccepted},
+			expectError:   false,
+		},
+		{
+			description:   "202 is rejected when only 200 is configured",
+			acceptedCodes: []int{http.StatusOK},
+			expectError:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		r := &reconciler{
+			config: Config{
+				AcceptableStatusCodes: tc.acceptedCodes,
+			},
+		}
+		route := routeForServer(server, "8080")
+		probeResult, err := r.probeRouteEndpoint(context.TODO(), route)
+		if tc.expectError {
+			if err == nil {
+				t.Errorf("%s: expected probeRouteEndpoint to return an error, but it did not", tc.description)
+			} else if !errors.Is(err, ErrCanaryUnexpectedStatus) {
+				t.Errorf("%s: expected an unexpected status error, but got %v", tc.description, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: probeRouteEndpoint returned an unexpected error: %v", tc.description, err)
+		} else if probeResult.Latency <= 0 {
+			t.Errorf("%s: expected an accepted probe to record a positive latency, but got %v", tc.description, probeResult.Latency)
+		}
+	}
+}
+
+// TestProbeEndpoint verifies that the low-level probe validates a
+// response from an arbitrary endpoint, including the optional port
+// echo check, without requiring a route.
+func TestProbeEndpoint(t *testing.T) {
+	testCases := []struct {
+		description   string
+		handler       http.HandlerFunc
+		expectedBody  string
+		expectedPort  string
+		expectedError error
+	}{
+		{
+			description: "successful probe",
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set(echoServerPortAckHeader, "8080")
+				fmt.Fprint(w, CanaryHealthcheckResponse)
+			},
+			expectedBody: CanaryHealthcheckResponse,
+			expectedPort: "8080",
+		},
+		{
+			description: "empty expected port skips the port echo check",
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				fmt.Fprint(w, CanaryHealthcheckResponse)
+			},
+			expectedBody: CanaryHealthcheckResponse,
+		},
+		{
+			description: "wrong port echoed",
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set(echoServerPortAckHeader, "8888")
+				fmt.Fprint(w, CanaryHealthcheckResponse)
+			},
+			expectedBody:  CanaryHealthcheckResponse,
+			expectedPort:  "8080",
+			expectedError: ErrCanaryWrongPort,
+		},
+		{
+			description: "body mismatch",
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set(echoServerPortAckHeader, "8080")
+				fmt.Fprint(w, "unexpected body")
+			},
+			expectedBody:  CanaryHealthcheckResponse,
+			expectedPort:  "8080",
+			expectedError: ErrCanaryBodyMismatch,
+		},
+		{
+			description: "unavailable endpoint",
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set(echoServerPortAckHeader, "8080")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				fmt.Fprint(w, CanaryHealthcheckResponse)
+			},
+			expectedBody:  CanaryHealthcheckResponse,
+			expectedPort:  "8080",
+			expectedError: ErrCanaryRouteUnavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+
+			r := &reconciler{
+				config: Config{},
+			}
+			client := &http.Client{Timeout: 1 * time.Second}
+
+			probeResult, err := r.probeEndpoint(context.TODO(), server.URL, tc.expectedBody, tc.expectedPort, client)
+			if tc.expectedError != nil {
+				if !errors.Is(err, tc.expectedError) {
+					t.Fatalf("expected error %q, but got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("probeEndpoint returned an unexpected error: %v", err)
+			}
+			if probeResult.Latency <= 0 {
+				t.Errorf("expected a positive probe latency, but got %v", probeResult.Latency)
+			}
+		})
+	}
+}
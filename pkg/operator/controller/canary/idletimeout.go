@@ -0,0 +1,93 @@
+package canary
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+// defaultIdleTimeoutProbeWindow is how long the idle-timeout probe
+// leaves a connection idle before attempting to reuse it when
+// Config.IdleTimeoutProbeWindow is unset. The window should exceed the
+// router's configured idle timeout.
+const defaultIdleTimeoutProbeWindow = 5 * time.Minute
+
+// probeIdleTimeout verifies that the router terminates idle
+// connections. It makes one request over a keep-alive connection,
+// leaves the connection idle past the expected idle timeout, and then
+// attempts to reuse it, expecting the router to have closed it.
+func (r *reconciler) probeIdleTimeout(route *routev1.Route) error {
+	window := r.config.IdleTimeoutProbeWindow
+	if window == 0 {
+		window = defaultIdleTimeoutProbeWindow
+	}
+
+	conn, err := r.dialRouteProbeConn(route)
+	if err != nil {
+		return fmt.Errorf("error dialing %q for canary idle timeout probe: %v", route.Spec.Host, err)
+	}
+	defer conn.Close()
+
+	options := r.probeOptionsForHost(route.Spec.Host)
+	return checkIdleConnectionClosed(conn, route.Spec.Host, window, options.Timeout)
+}
+
+// checkIdleConnectionClosed makes one request over the given
+// connection, leaves the connection idle for idleWait, and then
+// attempts a second request, returning nil if the peer closed the
+// connection during the idle window. An error is returned if the
+// reused connection still serves responses, indicating the idle
+// timeout is not being enforced.
+func checkIdleConnectionClosed(conn net.Conn, host string, idleWait, timeout time.Duration) error {
+	request := fmt.Sprintf("GET / HTTP/1.1\r\nHost: %s\r\n\r\n", host)
+	reader := bufio.NewReader(conn)
+
+	// Establish the keep-alive connection with an initial exchange.
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return fmt.Errorf("error setting deadline for canary idle timeout probe: %v", err)
+	}
+	if _, err := conn.Write([]byte(request)); err != nil {
+		return fmt.Errorf("error writing initial canary idle timeout probe request: %v", err)
+	}
+	response, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		return fmt.Errorf("error reading initial canary idle timeout probe response: %v", err)
+	}
+	if _, err := io.Copy(ioutil.Discard, response.Body); err != nil {
+		response.Body.Close()
+		return fmt.Errorf("error reading initial canary idle timeout probe response body: %v", err)
+	}
+	response.Body.Close()
+	if response.Close {
+		// The connection is not keep-alive, so idle reuse cannot be
+		// checked.
+		return nil
+	}
+
+	// Leave the connection idle past the expected idle timeout.
+	time.Sleep(idleWait)
+
+	// Attempt to reuse the connection; the router should have closed
+	// it during the idle window.
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return fmt.Errorf("error setting deadline for canary idle timeout probe reuse: %v", err)
+	}
+	if _, err := conn.Write([]byte(request)); err != nil {
+		return nil
+	}
+	if _, err := http.ReadResponse(reader, nil); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		return fmt.Errorf("error reading reused canary idle timeout probe response: %v", err)
+	}
+
+	return fmt.Errorf("expected connection to be closed after %v idle, but it still served a response", idleWait)
+}
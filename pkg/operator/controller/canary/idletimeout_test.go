@@ -0,0 +1,48 @@
+package canary
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckIdleConnectionClosed(t *testing.T) {
+	t.Run("server with a short idle timeout closes the connection", func(t *testing.T) {
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			fmt.Fprint(w, CanaryHealthcheckResponse)
+		}))
+		server.Config.IdleTimeout = 50 * time.Millisecond
+		server.Start()
+		defer server.Close()
+
+		conn, err := net.Dial("tcp", server.Listener.Addr().String())
+		if err != nil {
+			t.Fatalf("failed to dial test server: %v", err)
+		}
+		defer conn.Close()
+
+		if err := checkIdleConnectionClosed(conn, "canary.example.com", 500*time.Millisecond, 5*time.Second); err != nil {
+			t.Errorf("expected idle timeout probe to pass against a server with an idle timeout, but got: %v", err)
+		}
+	})
+
+	t.Run("server without an idle timeout keeps the connection open", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			fmt.Fprint(w, CanaryHealthcheckResponse)
+		}))
+		defer server.Close()
+
+		conn, err := net.Dial("tcp", server.Listener.Addr().String())
+		if err != nil {
+			t.Fatalf("failed to dial test server: %v", err)
+		}
+		defer conn.Close()
+
+		if err := checkIdleConnectionClosed(conn, "canary.example.com", 100*time.Millisecond, 5*time.Second); err == nil {
+			t.Error("expected idle timeout probe to fail against a server without an idle timeout, but it passed")
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package canary
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// defaultInsightsSummaryConfigMapName is the name of the ConfigMap that
+// the canary health summary is written to when
+// Config.InsightsSummaryConfigMapName is unset.
+const defaultInsightsSummaryConfigMapName = "canary-health-summary"
+
+// probeStats is an aggregate summary of canary probe outcomes, suitable
+// for gathering by the insights operator.
+type probeStats struct {
+	// Total is the total number of canary checks performed.
+	Total int
+	// Successes is the number of successful canary checks.
+	Successes int
+	// Failures is the number of failed canary checks.
+	Failures int
+	// LastCheckSuccess indicates whether the most recent canary
+	// check succeeded.
+	LastCheckSuccess bool
+}
+
+// recordProbeStats records the given probe outcome in the reconciler's
+// aggregate stats and returns a snapshot of the updated stats. The
+// stats are guarded by introspectMu because the introspection server
+// reads them concurrently with the polling goroutine.
+func (r *reconciler) recordProbeStats(success bool) probeStats {
+	r.introspectMu.Lock()
+	defer r.introspectMu.Unlock()
+
+	r.stats.Total++
+	if success {
+		r.stats.Successes++
+	} else {
+		r.stats.Failures++
+	}
+	r.stats.LastCheckSuccess = success
+
+	return r.stats
+}
+
+// insightsSummaryConfigMapName returns the namespaced name of the
+// ConfigMap that the canary health summary is written to.
+func (r *reconciler) insightsSummaryConfigMapName() types.NamespacedName {
+	name := r.config.InsightsSummaryConfigMapName
+	if len(name) == 0 {
+		name = defaultInsightsSummaryConfigMapName
+	}
+	return types.NamespacedName{
+		Namespace: r.canaryNamespace(),
+		Name:      name,
+	}
+}
+
+// updateInsightsSummary writes the given probe stats to the canary
+// health summary ConfigMap, creating the ConfigMap if necessary. The
+// summary is replaced wholesale each cycle so that readers always see a
+// consistent snapshot.
+func (r *reconciler) updateInsightsSummary(stats probeStats) error {
+	name := r.insightsSummaryConfigMapName()
+	desired := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: name.Namespace,
+			Name:      name.Name,
+			Labels: map[string]string{
+				manifests.OwningIngressCanaryCheckLabel: canaryControllerName,
+			},
+		},
+		Data: map[string]string{
+			"totalChecks":      strconv.Itoa(stats.Total),
+			"successfulChecks": strconv.Itoa(stats.Successes),
+			"failedChecks":     strconv.Itoa(stats.Failures),
+			"lastCheckSuccess": strconv.FormatBool(stats.LastCheckSuccess),
+		},
+	}
+
+	current := &corev1.ConfigMap{}
+	if err := r.client.Get(context.TODO(), name, current); err != nil {
+		if errors.IsNotFound(err) {
+			if err := r.client.Create(context.TODO(), desired); err != nil {
+				return fmt.Errorf("failed to create canary insights summary configmap %s/%s: %v", name.Namespace, name.Name, err)
+			}
+			return nil
+		}
+		return fmt.Errorf("failed to get canary insights summary configmap %s/%s: %v", name.Namespace, name.Name, err)
+	}
+
+	updated := current.DeepCopy()
+	updated.Data = desired.Data
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("failed to update canary insights summary configmap %s/%s: %v", name.Namespace, name.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package canary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestUpdateInsightsSummary(t *testing.T) {
+	fakeClient := fake.NewClientBuilder().WithScheme(operatorclient.GetScheme()).Build()
+	r := &reconciler{
+		client: fakeClient,
+		config: Config{
+			EnableInsightsSummary: true,
+		},
+	}
+
+	// The first update creates the summary ConfigMap.
+	if err := r.updateInsightsSummary(probeStats{Total: 1, Successes: 1, LastCheckSuccess: true}); err != nil {
+		t.Fatalf("updateInsightsSummary returned an error: %v", err)
+	}
+
+	name := r.insightsSummaryConfigMapName()
+	cm := &corev1.ConfigMap{}
+	if err := fakeClient.Get(context.TODO(), name, cm); err != nil {
+		t.Fatalf("failed to get canary insights summary configmap: %v", err)
+	}
+
+	expected := map[string]string{
+		"totalChecks":      "1",
+		"successfulChecks": "1",
+		"failedChecks":     "0",
+		"lastCheckSuccess": "true",
+	}
+	if !cmp.Equal(cm.Data, expected) {
+		t.Errorf("expected summary data %v, but got %v", expected, cm.Data)
+	}
+
+	// Subsequent updates replace the summary in place.
+	if err := r.updateInsightsSummary(probeStats{Total: 2, Successes: 1, Failures: 1, LastCheckSuccess: false}); err != nil {
+		t.Fatalf("updateInsightsSummary returned an error: %v", err)
+	}
+	if err := fakeClient.Get(context.TODO(), name, cm); err != nil {
+		t.Fatalf("failed to get canary insights summary configmap: %v", err)
+	}
+
+	expected = map[string]string{
+		"totalChecks":      "2",
+		"successfulChecks": "1",
+		"failedChecks":     "1",
+		"lastCheckSuccess": "false",
+	}
+	if !cmp.Equal(cm.Data, expected) {
+		t.Errorf("expected summary data %v, but got %v", expected, cm.Data)
+	}
+}
@@ -0,0 +1,129 @@
+package canary
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+const (
+	// defaultIntrospectionAddr is the localhost address that the
+	// introspection endpoint binds to when
+	// Config.IntrospectionAddr is unset.
+	defaultIntrospectionAddr = "127.0.0.1:39500"
+	// introspectionResultBufferSize is how many recent probe results
+	// the reconciler retains for introspection.
+	introspectionResultBufferSize = 20
+)
+
+// probeRecord is a single probe outcome retained for introspection.
+type probeRecord struct {
+	// Time is when the probe completed.
+	Time time.Time `json:"time"`
+	// Host is the host that was probed.
+	Host string `json:"host"`
+	// Success indicates whether the probe succeeded.
+	Success bool `json:"success"`
+	// Error is the probe's error message, if any.
+	Error string `json:"error,omitempty"`
+	// BackendAddr is the backend that served the probe, if recorded.
+	BackendAddr string `json:"backendAddr,omitempty"`
+}
+
+// introspectionState is the read-only snapshot of the reconciler's
+// internal state served by the introspection endpoint.
+type introspectionState struct {
+	// EffectiveConfig is the probe configuration currently in effect.
+	EffectiveConfig effectiveProbeConfig `json:"effectiveConfig"`
+	// Stats aggregates probe outcomes since the operator started.
+	Stats probeStats `json:"stats"`
+	// Hosts maps each probed host to its most recent probe result.
+	Hosts map[string]probeRecord `json:"hosts"`
+	// RecentResults is a ring buffer of the last probe results,
+	// oldest first.
+	RecentResults []probeRecord `json:"recentResults"`
+}
+
+// recordProbeResult retains the given probe outcome in the
+// introspection ring buffer.
+func (r *reconciler) recordProbeResult(host string, result *ProbeResult, err error) {
+	record := probeRecord{
+		Time:    time.Now(),
+		Host:    host,
+		Success: err == nil,
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	if result != nil {
+		record.BackendAddr = result.BackendAddr
+	}
+
+	r.introspectMu.Lock()
+	defer r.introspectMu.Unlock()
+	r.recentResults = append(r.recentResults, record)
+	if len(r.recentResults) > introspectionResultBufferSize {
+		r.recentResults = r.recentResults[len(r.recentResults)-introspectionResultBufferSize:]
+	}
+}
+
+// introspectionSnapshot builds a read-only snapshot of the
+// reconciler's internal state.
+func (r *reconciler) introspectionSnapshot() introspectionState {
+	r.introspectMu.Lock()
+	defer r.introspectMu.Unlock()
+
+	state := introspectionState{
+		EffectiveConfig: r.effectiveConfig(),
+		Stats:           r.stats,
+		Hosts:           map[string]probeRecord{},
+		RecentResults:   append([]probeRecord{}, r.recentResults...),
+	}
+	for _, record := range r.recentResults {
+		state.Hosts[record.Host] = record
+	}
+	return state
+}
+
+// introspectionHandler returns the HTTP handler serving the
+// introspection endpoint.
+func (r *reconciler) introspectionHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/canary", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(r.introspectionSnapshot()); err != nil {
+			log.Error(err, "error encoding canary introspection state")
+		}
+	})
+	return mux
+}
+
+// startIntrospectionServer serves the reconciler's internal state over
+// a read-only HTTP/JSON endpoint bound to localhost for advanced
+// debugging. The server shuts down when the given context is canceled.
+func (r *reconciler) startIntrospectionServer(ctx context.Context) {
+	addr := r.config.IntrospectionAddr
+	if len(addr) == 0 {
+		addr = defaultIntrospectionAddr
+	}
+
+	server := &http.Server{
+		Addr:    addr,
+		Handler: r.introspectionHandler(),
+	}
+
+	go func() {
+		<-ctx.Done()
+		if err := server.Close(); err != nil {
+			log.Error(err, "error closing canary introspection server")
+		}
+	}()
+
+	go func() {
+		log.Info("starting canary introspection server", "addr", addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Error(err, "canary introspection server stopped")
+		}
+	}()
+}
@@ -0,0 +1,134 @@
+package canary
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIntrospectionEndpoint(t *testing.T) {
+	r := &reconciler{
+		config: Config{
+			EnableIntrospection: true,
+			CompressionProbe:    true,
+		},
+		stats: probeStats{
+			Total:            3,
+			Successes:        2,
+			Failures:         1,
+			LastCheckSuccess: true,
+		},
+	}
+
+	r.recordProbeResult("canary.apps.example.com", &ProbeResult{BackendAddr: "10.0.0.1:8080"}, nil)
+	r.recordProbeResult("canary.apps.example.com", nil, errors.New("probe failed"))
+	r.recordProbeResult("other.apps.example.com", &ProbeResult{BackendAddr: "10.0.0.2:8080"}, nil)
+
+	server := httptest.NewServer(r.introspectionHandler())
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/debug/canary")
+	if err != nil {
+		t.Fatalf("failed to get introspection state: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, response.StatusCode)
+	}
+
+	state := introspectionState{}
+	if err := json.NewDecoder(response.Body).Decode(&state); err != nil {
+		t.Fatalf("failed to decode introspection state: %v", err)
+	}
+
+	if state.EffectiveConfig.CheckInterval != canaryCheckFrequency.String() {
+		t.Errorf("expected effective config check interval %q, but got %q", canaryCheckFrequency.String(), state.EffectiveConfig.CheckInterval)
+	}
+
+	if state.Stats.Total != 3 || state.Stats.Successes != 2 || state.Stats.Failures != 1 {
+		t.Errorf("expected stats 3/2/1, but got %d/%d/%d", state.Stats.Total, state.Stats.Successes, state.Stats.Failures)
+	}
+
+	if len(state.RecentResults) != 3 {
+		t.Fatalf("expected 3 recent results, but got %d", len(state.RecentResults))
+	}
+
+	last, ok := state.Hosts["canary.apps.example.com"]
+	if !ok {
+		t.Fatal("expected per-host state for canary.apps.example.com")
+	}
+	if last.Success {
+		t.Error("expected the last result for canary.apps.example.com to be a failure")
+	}
+	if last.Error != "probe failed" {
+		t.Errorf("expected per-host error %q, but got %q", "probe failed", last.Error)
+	}
+
+	other, ok := state.Hosts["other.apps.example.com"]
+	if !ok {
+		t.Fatal("expected per-host state for other.apps.example.com")
+	}
+	if other.BackendAddr != "10.0.0.2:8080" {
+		t.Errorf("expected per-host backend address %q, but got %q", "10.0.0.2:8080", other.BackendAddr)
+	}
+}
+
+func TestRecordProbeResultRingBuffer(t *testing.T) {
+	r := &reconciler{}
+	for i := 0; i < introspectionResultBufferSize+5; i++ {
+		r.recordProbeResult("canary.apps.example.com", nil, fmt.Errorf("failure %d", i))
+	}
+
+	state := r.introspectionSnapshot()
+	if len(state.RecentResults) != introspectionResultBufferSize {
+		t.Fatalf("expected the ring buffer to hold %d results, but got %d", introspectionResultBufferSize, len(state.RecentResults))
+	}
+	oldest := state.RecentResults[0]
+	if oldest.Error != "failure 5" {
+		t.Errorf("expected the oldest retained result to be %q, but got %q", "failure 5", oldest.Error)
+	}
+}
+
+// TestIntrospectionConcurrentWithProbeRecording exercises the
+// introspection endpoint while probe outcomes are being recorded so
+// that the race detector covers the shared stats and result buffer.
+func TestIntrospectionConcurrentWithProbeRecording(t *testing.T) {
+	r := &reconciler{
+		config: Config{
+			EnableIntrospection: true,
+		},
+	}
+
+	server := httptest.NewServer(r.introspectionHandler())
+	defer server.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			r.recordProbeResult("canary.apps.example.com", &ProbeResult{BackendAddr: "10.0.0.1:8080"}, nil)
+			r.recordProbeStats(i%2 == 0)
+		}
+	}()
+
+	for i := 0; i < 20; i++ {
+		response, err := http.Get(server.URL + "/debug/canary")
+		if err != nil {
+			t.Fatalf("failed to get introspection state: %v", err)
+		}
+		state := introspectionState{}
+		if err := json.NewDecoder(response.Body).Decode(&state); err != nil {
+			t.Fatalf("failed to decode introspection state: %v", err)
+		}
+		response.Body.Close()
+		if state.Stats.Total != state.Stats.Successes+state.Stats.Failures {
+			t.Fatalf("expected a consistent stats snapshot, but got total %d with %d successes and %d failures", state.Stats.Total, state.Stats.Successes, state.Stats.Failures)
+		}
+	}
+
+	<-done
+}
@@ -0,0 +1,45 @@
+package canary
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+const (
+	// CanaryMaintenanceModeAnnotation is an annotation on the default
+	// ingress controller that specifies whether the canary daemonset
+	// should be scaled to zero during known maintenance. When the
+	// annotation has a value of "true", the canary daemonset schedules
+	// no pods and canary checks are suspended; when the annotation is
+	// removed or set to any other value, the daemonset's pods and the
+	// canary checks are restored.
+	CanaryMaintenanceModeAnnotation = "ingress.operator.openshift.io/canary-maintenance-mode"
+
+	// canaryMaintenanceNodeSelectorKey is a node label key that no node
+	// carries. Selecting on it is the daemonset equivalent of scaling
+	// to zero replicas; the manifest's node selector is restored when
+	// maintenance mode ends.
+	canaryMaintenanceNodeSelectorKey = "ingress.operator.openshift.io/canary-maintenance"
+)
+
+// setMaintenanceNodeSelector replaces the daemonset's pod template node
+// selector with one that no node satisfies so that the daemonset
+// schedules no pods.
+func setMaintenanceNodeSelector(daemonset *appsv1.DaemonSet) {
+	daemonset.Spec.Template.Spec.NodeSelector = map[string]string{
+		canaryMaintenanceNodeSelectorKey: "true",
+	}
+}
+
+// setMaintenanceMode records whether maintenance mode is enabled.
+func (r *reconciler) setMaintenanceMode(enabled bool) {
+	r.maintenanceMu.Lock()
+	defer r.maintenanceMu.Unlock()
+	r.maintenanceMode = enabled
+}
+
+// inMaintenanceMode returns true if maintenance mode is enabled.
+func (r *reconciler) inMaintenanceMode() bool {
+	r.maintenanceMu.Lock()
+	defer r.maintenanceMu.Unlock()
+	return r.maintenanceMode
+}
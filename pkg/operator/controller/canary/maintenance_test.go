@@ -0,0 +1,51 @@
+package canary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMaintenanceModeScalesDaemonSetToZero(t *testing.T) {
+	r := &reconciler{
+		client: fake.NewClientBuilder().WithScheme(operatorclient.GetScheme()).Build(),
+		config: Config{
+			CanaryImage: "openshift/origin-cluster-ingress-operator:latest",
+		},
+	}
+
+	haveDs, daemonset, err := r.ensureCanaryDaemonSet(context.TODO())
+	if err != nil {
+		t.Fatalf("failed to ensure canary daemonset: %v", err)
+	} else if !haveDs {
+		t.Fatal("expected canary daemonset to exist")
+	}
+	originalNodeSelector := daemonset.Spec.Template.Spec.NodeSelector
+
+	// Enabling maintenance mode replaces the node selector with one
+	// that no node satisfies, scheduling zero canary pods.
+	r.setMaintenanceMode(true)
+	if _, daemonset, err = r.ensureCanaryDaemonSet(context.TODO()); err != nil {
+		t.Fatalf("failed to ensure canary daemonset in maintenance mode: %v", err)
+	}
+	expectedNodeSelector := map[string]string{
+		canaryMaintenanceNodeSelectorKey: "true",
+	}
+	if !cmp.Equal(daemonset.Spec.Template.Spec.NodeSelector, expectedNodeSelector) {
+		t.Errorf("expected maintenance node selector %v, but got %v", expectedNodeSelector, daemonset.Spec.Template.Spec.NodeSelector)
+	}
+
+	// Disabling maintenance mode restores the original node selector.
+	r.setMaintenanceMode(false)
+	if _, daemonset, err = r.ensureCanaryDaemonSet(context.TODO()); err != nil {
+		t.Fatalf("failed to ensure canary daemonset after maintenance mode: %v", err)
+	}
+	if !cmp.Equal(daemonset.Spec.Template.Spec.NodeSelector, originalNodeSelector) {
+		t.Errorf("expected node selector %v to be restored, but got %v", originalNodeSelector, daemonset.Spec.Template.Spec.NodeSelector)
+	}
+}
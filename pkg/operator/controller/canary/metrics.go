@@ -3,48 +3,351 @@ package canary
 import (
 	"context"
 	"net/http"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+
+	"github.com/tcnksm/go-httpstat"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	ctrlruntimemetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
+// Individual metrics are stored as public variables
+// so that metrics can be globally controlled.
 var (
+	CanaryRequestTime *prometheus.HistogramVec
+
+	CanaryDNSLookupTime *prometheus.HistogramVec
+
+	CanaryTCPConnectTime *prometheus.HistogramVec
+
+	CanaryTLSHandshakeTime *prometheus.HistogramVec
+
+	CanaryServerProcessingTime *prometheus.HistogramVec
+
+	CanaryEndpointWrongPortEcho prometheus.Counter
+
+	CanaryRouteReachable *prometheus.GaugeVec
+
+	CanaryLastSuccessTimestamp *prometheus.GaugeVec
+
+	CanaryRouteDNSError *prometheus.CounterVec
+
+	CanaryDNSFailures prometheus.Counter
+
+	CanaryServiceReachable prometheus.Gauge
+
+	CanaryInfo *prometheus.GaugeVec
+
+	CanaryLatencySLOViolations prometheus.Counter
+
+	CanaryRouteMisconfigured prometheus.Counter
+
+	CanaryAvailableRotationPorts prometheus.Gauge
+
+	CanaryReconcileErrors *prometheus.CounterVec
+
+	CanaryProbeAttempts prometheus.Counter
+
+	CanaryProbeSuccesses prometheus.Counter
+
+	CanaryProbeFailures prometheus.Counter
+
+	CanaryImageRollouts prometheus.Counter
+
+	CanaryConnectionReuseLeaks prometheus.Counter
+
+	CanaryRoutePortRotations prometheus.Counter
+
+	CanaryRoutePortRotationFailures prometheus.Counter
+
+	CanaryRouteSuccessRatio *prometheus.GaugeVec
+
+	CanaryRouteInsecurePolicy *prometheus.GaugeVec
+
+	CanaryPollerLastTickTimestamp prometheus.Gauge
+
+	CanaryRouterWedged prometheus.Gauge
+
+	// metricsList is the prometheus collector list populated by
+	// initCanaryMetrics.
+	metricsList []prometheus.Collector
+
+	// defaultRequestTimeBuckets are the millisecond bucket boundaries
+	// for the canary check duration histogram when no override is
+	// configured.
+	defaultRequestTimeBuckets = []float64{25, 50, 100, 200, 400, 800, 1600}
+
+	// metricsPrefix and requestTimeBuckets record the currently applied
+	// collector settings so that re-creating the collectors for one
+	// setting preserves the other.
+	metricsPrefix      string
+	requestTimeBuckets []float64
+)
+
+func init() {
+	initCanaryMetrics()
+}
+
+// SetMetricsPrefix re-creates the canary metric collectors with the
+// given Prometheus namespace prefix applied to every metric family. It
+// must be called before StartMetricsListener registers the metrics.
+func SetMetricsPrefix(prefix string) {
+	metricsPrefix = prefix
+	initCanaryMetrics()
+}
+
+// SetCanaryRequestTimeBuckets re-creates the canary metric collectors
+// with the given millisecond bucket boundaries for the canary check
+// duration histogram, so that bucket resolution can be tuned around a
+// cluster's expected latency band. It must be called before
+// StartMetricsListener registers the metrics.
+func SetCanaryRequestTimeBuckets(buckets []float64) {
+	requestTimeBuckets = buckets
+	initCanaryMetrics()
+}
+
+// initCanaryMetrics creates the canary metric collectors with the
+// currently applied settings and rebuilds the registration list.
+func initCanaryMetrics() {
+	prefix := metricsPrefix
+
+	buckets := requestTimeBuckets
+	if len(buckets) == 0 {
+		buckets = defaultRequestTimeBuckets
+	}
+
 	CanaryRequestTime = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
-			Name:    "ingress_canary_check_duration",
-			Help:    "Canary endpoint request time in ms",
-			Buckets: []float64{25, 50, 100, 200, 400, 800, 1600},
+			Namespace: prefix,
+			Name:      "ingress_canary_check_duration",
+			Help:      "Canary endpoint request time in ms. The host label is the canary route host, or the configured stable label when one is set to bound series cardinality",
+			Buckets:   buckets,
+		}, []string{"host"})
+
+	// Per-phase histograms use second-based buckets sized for the
+	// short phases of a canary request. Cardinality stays bounded
+	// because only the single canary host is observed.
+	phaseBuckets := []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5}
+
+	CanaryDNSLookupTime = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: prefix,
+			Name:      "ingress_canary_dns_lookup_seconds",
+			Help:      "Canary endpoint DNS lookup time in seconds",
+			Buckets:   phaseBuckets,
+		}, []string{"host"})
+
+	CanaryTCPConnectTime = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: prefix,
+			Name:      "ingress_canary_tcp_connect_seconds",
+			Help:      "Canary endpoint TCP connection time in seconds",
+			Buckets:   phaseBuckets,
+		}, []string{"host"})
+
+	CanaryTLSHandshakeTime = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: prefix,
+			Name:      "ingress_canary_tls_handshake_seconds",
+			Help:      "Canary endpoint TLS handshake time in seconds",
+			Buckets:   phaseBuckets,
+		}, []string{"host"})
+
+	CanaryServerProcessingTime = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: prefix,
+			Name:      "ingress_canary_server_processing_seconds",
+			Help:      "Canary endpoint server processing (time to first byte) in seconds",
+			Buckets:   phaseBuckets,
 		}, []string{"host"})
 
 	CanaryEndpointWrongPortEcho = prometheus.NewCounter(
 		prometheus.CounterOpts{
-			Name: "ingress_canary_endpoint_wrong_port_echo",
-			Help: "The ingress canary application received a test request on an incorrect port which may indicate that the router is \"wedged\"",
+			Namespace: prefix,
+			Name:      "ingress_canary_endpoint_wrong_port_echo",
+			Help:      "The ingress canary application received a test request on an incorrect port which may indicate that the router is \"wedged\"",
 		})
 
 	CanaryRouteReachable = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
-			Name: "ingress_canary_route_reachable",
-			Help: "A gauge set to 0 or 1 to signify whether or not the canary application is reachable via a route",
+			Namespace: prefix,
+			Name:      "ingress_canary_route_reachable",
+			Help:      "A gauge set to 0 or 1 to signify whether or not the canary application is reachable via a route",
+		}, []string{"host"})
+
+	CanaryLastSuccessTimestamp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: prefix,
+			Name:      "ingress_canary_last_success_timestamp_seconds",
+			Help:      "The Unix timestamp of the last successful canary check, so that alerts can fire when the canary has not succeeded for some period regardless of scrape gaps",
 		}, []string{"host"})
 
 	CanaryRouteDNSError = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
-			Name: "ingress_canary_route_DNS_error",
-			Help: "A counter tracking canary route DNS lookup errors",
+			Namespace: prefix,
+			Name:      "ingress_canary_route_DNS_error",
+			Help:      "A counter tracking canary route DNS lookup errors",
 		}, []string{"host", "dnsServer"})
 
-	// Populate prometheus collector.
-	// Individual metrics are stored as public variables
-	// so that metrics can be globally controlled.
+	CanaryDNSFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: prefix,
+			Name:      "ingress_canary_dns_failures_total",
+			Help:      "A counter tracking canary checks that failed to resolve the route host, which usually indicates a cluster DNS or infrastructure issue rather than an ingress issue",
+		})
+
+	CanaryServiceReachable = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: prefix,
+			Name:      "ingress_canary_service_reachable",
+			Help:      "A gauge set to 0 or 1 to signify whether or not the canary application is reachable via the canary service's ClusterIP, bypassing the router",
+		})
+
+	CanaryInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: prefix,
+			Name:      "ingress_canary_info",
+			Help:      "An info-style gauge set to 1, whose image label carries the currently deployed canary container image, so that body-mismatch failures can be correlated with image overrides",
+		}, []string{"image"})
+
+	CanaryLatencySLOViolations = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: prefix,
+			Name:      "ingress_canary_latency_slo_violations_total",
+			Help:      "A counter tracking successful canary checks whose measured latency exceeded the configured maximum, catching routers that are up but degraded",
+		})
+
+	CanaryRouteMisconfigured = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: prefix,
+			Name:      "ingress_canary_route_misconfigured_total",
+			Help:      "A counter tracking canary checks and rotations that were skipped because the canary route is misconfigured, for example missing its Spec.Port",
+		})
+
+	CanaryAvailableRotationPorts = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: prefix,
+			Name:      "ingress_canary_available_rotation_ports",
+			Help:      "A gauge reporting how many ports the canary service exposes for route port rotation; rotation and wedge detection are inactive below two",
+		})
+
+	CanaryReconcileErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: prefix,
+			Name:      "ingress_canary_reconcile_errors_total",
+			Help:      "A counter tracking canary reconcile failures, labeled by the ensure step (namespace, daemonset, service, or route) that failed",
+		}, []string{"step"})
+
+	CanaryProbeAttempts = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: prefix,
+			Name:      "ingress_canary_probe_attempts_total",
+			Help:      "A counter tracking every canary probe attempt, including retries, so that error rates can be computed against the success and failure counters",
+		})
+
+	CanaryProbeSuccesses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: prefix,
+			Name:      "ingress_canary_probe_success_total",
+			Help:      "A counter tracking canary probe attempts that succeeded",
+		})
+
+	CanaryProbeFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: prefix,
+			Name:      "ingress_canary_probe_failure_total",
+			Help:      "A counter tracking canary probe attempts that failed",
+		})
+
+	CanaryImageRollouts = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: prefix,
+			Name:      "ingress_canary_image_rollouts_total",
+			Help:      "A counter tracking how many times the canary image has been updated, triggering a daemonset rollout",
+		})
+
+	CanaryConnectionReuseLeaks = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: prefix,
+			Name:      "ingress_canary_connection_reuse_leaks_total",
+			Help:      "A counter tracking wrong-port echoes after a route rotation that were caused by a reused keep-alive connection pinned to the old backend rather than a wedged router",
+		})
+
+	CanaryRoutePortRotations = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: prefix,
+			Name:      "ingress_canary_route_port_rotations_total",
+			Help:      "A counter tracking how many times the canary route's target port has been rotated, so that rotation timing can be correlated with latency spikes or wedge detection",
+		})
+
+	CanaryRoutePortRotationFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: prefix,
+			Name:      "ingress_canary_route_port_rotation_failures_total",
+			Help:      "A counter tracking canary route port rotations that failed, for example because the route could not be updated",
+		})
+
+	CanaryRouteSuccessRatio = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: prefix,
+			Name:      "ingress_canary_route_success_ratio",
+			Help:      "A gauge reporting the fraction of successful canary checks over a sliding window of recent probes",
+		}, []string{"host"})
+
+	CanaryRouteInsecurePolicy = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: prefix,
+			Name:      "ingress_canary_route_insecure_edge_termination_policy",
+			Help:      "A gauge with one series per insecure edge termination policy value, set to 1 for the canary route's current policy",
+		}, []string{"policy"})
+
+	CanaryPollerLastTickTimestamp = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: prefix,
+			Name:      "ingress_canary_poller_last_tick_timestamp_seconds",
+			Help:      "The Unix timestamp of the canary polling loop's most recent cycle, so that alerts can fire if the poller stalls",
+		})
+
+	CanaryRouterWedged = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: prefix,
+			Name:      "ingress_canary_router_wedged",
+			Help:      "A gauge set to 1 when canary requests have consistently reached the wrong backend port, indicating the router is serving stale configuration",
+		})
+
 	metricsList = []prometheus.Collector{
 		CanaryRequestTime,
+		CanaryDNSLookupTime,
+		CanaryTCPConnectTime,
+		CanaryTLSHandshakeTime,
+		CanaryServerProcessingTime,
 		CanaryEndpointWrongPortEcho,
 		CanaryRouteReachable,
+		CanaryLastSuccessTimestamp,
 		CanaryRouteDNSError,
+		CanaryDNSFailures,
+		CanaryServiceReachable,
+		CanaryInfo,
+		CanaryLatencySLOViolations,
+		CanaryRouteMisconfigured,
+		CanaryAvailableRotationPorts,
+		CanaryReconcileErrors,
+		CanaryProbeAttempts,
+		CanaryProbeSuccesses,
+		CanaryProbeFailures,
+		CanaryRouteInsecurePolicy,
+		CanaryImageRollouts,
+		CanaryConnectionReuseLeaks,
+		CanaryRoutePortRotations,
+		CanaryRoutePortRotationFailures,
+		CanaryRouteSuccessRatio,
+		CanaryPollerLastTickTimestamp,
+		CanaryRouterWedged,
 	}
-)
+}
 
 // SetCanaryRouteMetric is a wrapper function to
 // mark the canary route as either online or offline.
@@ -56,6 +359,105 @@ func SetCanaryRouteReachableMetric(host string, status bool) {
 	}
 }
 
+// SetCanaryServiceReachableMetric marks the canary application as
+// either reachable or unreachable via the canary service's ClusterIP.
+func SetCanaryServiceReachableMetric(status bool) {
+	if status {
+		CanaryServiceReachable.Set(1)
+	} else {
+		CanaryServiceReachable.Set(0)
+	}
+}
+
+// SetCanaryAvailableRotationPortsMetric records how many ports the
+// canary service exposes for route port rotation.
+func SetCanaryAvailableRotationPortsMetric(count int) {
+	CanaryAvailableRotationPorts.Set(float64(count))
+}
+
+// SetCanaryInfoMetric records the currently deployed canary container
+// image on the info gauge, replacing any series for a previous image.
+func SetCanaryInfoMetric(image string) {
+	CanaryInfo.Reset()
+	CanaryInfo.WithLabelValues(image).Set(1)
+}
+
+// SetCanaryLastSuccessTimeMetric records when the canary route was
+// last successfully probed, as a Unix timestamp.
+func SetCanaryLastSuccessTimeMetric(host string, t time.Time) {
+	CanaryLastSuccessTimestamp.WithLabelValues(host).Set(float64(t.Unix()))
+}
+
+// SetCanaryPollerHeartbeatMetric records when the canary polling loop
+// last started a cycle, as a Unix timestamp.
+func SetCanaryPollerHeartbeatMetric(t time.Time) {
+	CanaryPollerLastTickTimestamp.Set(float64(t.Unix()))
+}
+
+// SetCanaryRouterWedgedMetric records whether the router is considered
+// wedged based on consecutive wrong-port echoes.
+func SetCanaryRouterWedgedMetric(wedged bool) {
+	if wedged {
+		CanaryRouterWedged.Set(1)
+	} else {
+		CanaryRouterWedged.Set(0)
+	}
+}
+
+// SetCanaryRouteSuccessRatioMetric records the fraction of successful
+// canary checks over the recent probe window for the given host.
+func SetCanaryRouteSuccessRatioMetric(host string, ratio float64) {
+	CanaryRouteSuccessRatio.WithLabelValues(host).Set(ratio)
+}
+
+// SetCanaryRouteInsecurePolicyMetric records the canary route's
+// current insecure edge termination policy so that dashboards can
+// alert if the policy unexpectedly changes.
+func SetCanaryRouteInsecurePolicyMetric(route *routev1.Route) {
+	policy := routev1.InsecureEdgeTerminationPolicyNone
+	if route.Spec.TLS != nil && len(route.Spec.TLS.InsecureEdgeTerminationPolicy) != 0 {
+		policy = route.Spec.TLS.InsecureEdgeTerminationPolicy
+	}
+
+	// Reset the gauge so that only the current policy's series is set.
+	CanaryRouteInsecurePolicy.Reset()
+	CanaryRouteInsecurePolicy.WithLabelValues(string(policy)).Set(1)
+}
+
+// metricHostLabel returns the "host" label value used for the canary
+// latency histograms. When a stable label is configured, it replaces
+// the raw route host so that Prometheus series counts stay bounded
+// across route recreations, host changes, and future multi-route
+// support.
+func (r *reconciler) metricHostLabel(host string) string {
+	if len(r.config.StableMetricHostLabel) != 0 {
+		return r.config.StableMetricHostLabel
+	}
+	return host
+}
+
+// observeCanaryRequestTime records a probe's total time for the given
+// host, attaching the probe's trace ID as an OpenMetrics exemplar when
+// one is available so that latency spikes link directly to traces.
+func observeCanaryRequestTime(host string, milliseconds float64, traceID string) {
+	observer := CanaryRequestTime.WithLabelValues(host)
+	if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok && len(traceID) != 0 {
+		exemplarObserver.ObserveWithExemplar(milliseconds, prometheus.Labels{"trace_id": traceID})
+		return
+	}
+	observer.Observe(milliseconds)
+}
+
+// observeCanaryRequestPhases records the per-phase timing breakdown
+// that httpstat already measured for a successful probe, so that slow
+// canary checks can be attributed to DNS, connect, TLS, or the server.
+func observeCanaryRequestPhases(host string, result *httpstat.Result) {
+	CanaryDNSLookupTime.WithLabelValues(host).Observe(result.DNSLookup.Seconds())
+	CanaryTCPConnectTime.WithLabelValues(host).Observe(result.TCPConnection.Seconds())
+	CanaryTLSHandshakeTime.WithLabelValues(host).Observe(result.TLSHandshake.Seconds())
+	CanaryServerProcessingTime.WithLabelValues(host).Observe(result.ServerProcessing.Seconds())
+}
+
 // registerCanaryMetrics calls prometheus.Register
 // on each metric in metricsList, and returns on errors.
 func registerCanaryMetrics() error {
@@ -93,6 +495,9 @@ func StartMetricsListener(addr string, signal context.Context) {
 	log.Info("starting metrics listener on ", "addr", addr)
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", handler)
+	// Serve the canary's verdict for external monitors and load
+	// balancer health checks.
+	mux.HandleFunc("/canary/healthz", canaryHealthzHandler)
 	s := http.Server{Addr: addr, Handler: mux}
 
 	go func() {
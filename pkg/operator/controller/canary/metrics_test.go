@@ -0,0 +1,221 @@
+package canary
+
+import (
+	"strings"
+	"testing"
+
+	routev1 "github.com/openshift/api/route/v1"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestSetMetricsPrefix(t *testing.T) {
+	SetMetricsPrefix("acme")
+	defer SetMetricsPrefix("")
+
+	// Touch one series per vec so that every family is collectable.
+	CanaryRequestTime.WithLabelValues("host").Observe(1)
+	CanaryDNSLookupTime.WithLabelValues("host").Observe(1)
+	CanaryTCPConnectTime.WithLabelValues("host").Observe(1)
+	CanaryTLSHandshakeTime.WithLabelValues("host").Observe(1)
+	CanaryServerProcessingTime.WithLabelValues("host").Observe(1)
+	CanaryEndpointWrongPortEcho.Inc()
+	CanaryRouteReachable.WithLabelValues("host").Set(1)
+	CanaryLastSuccessTimestamp.WithLabelValues("host").Set(1)
+	CanaryRouteDNSError.WithLabelValues("host", "server").Inc()
+	CanaryInfo.WithLabelValues("image").Set(1)
+	CanaryReconcileErrors.WithLabelValues("route").Inc()
+	CanaryImageRollouts.Inc()
+	CanaryRouteInsecurePolicy.WithLabelValues("Redirect").Set(1)
+	CanaryRouteSuccessRatio.WithLabelValues("host").Set(1)
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(metricsList...)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather canary metrics: %v", err)
+	}
+	if len(families) != len(metricsList) {
+		t.Errorf("expected %d metric families, but got %d", len(metricsList), len(families))
+	}
+	for _, family := range families {
+		if !strings.HasPrefix(family.GetName(), "acme_ingress_canary_") {
+			t.Errorf("expected metric family %q to carry the configured prefix", family.GetName())
+		}
+	}
+}
+
+func TestSetCanaryRouteInsecurePolicyMetric(t *testing.T) {
+	testCases := []struct {
+		description    string
+		tls            *routev1.TLSConfig
+		expectedPolicy routev1.InsecureEdgeTerminationPolicyType
+	}{
+		{
+			description: "route with the redirect policy",
+			tls: &routev1.TLSConfig{
+				Termination:                   routev1.TLSTerminationEdge,
+				InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+			},
+			expectedPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+		},
+		{
+			description: "route with the allow policy",
+			tls: &routev1.TLSConfig{
+				Termination:                   routev1.TLSTerminationEdge,
+				InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyAllow,
+			},
+			expectedPolicy: routev1.InsecureEdgeTerminationPolicyAllow,
+		},
+		{
+			description:    "route without a TLS config",
+			tls:            nil,
+			expectedPolicy: routev1.InsecureEdgeTerminationPolicyNone,
+		},
+	}
+
+	for _, tc := range testCases {
+		route := &routev1.Route{
+			Spec: routev1.RouteSpec{
+				TLS: tc.tls,
+			},
+		}
+		SetCanaryRouteInsecurePolicyMetric(route)
+
+		if count := testutil.CollectAndCount(CanaryRouteInsecurePolicy); count != 1 {
+			t.Errorf("%s: expected 1 policy series, but got %d", tc.description, count)
+		}
+
+		value := testutil.ToFloat64(CanaryRouteInsecurePolicy.WithLabelValues(string(tc.expectedPolicy)))
+		if value != 1 {
+			t.Errorf("%s: expected policy series %q to be 1, but got %v", tc.description, tc.expectedPolicy, value)
+		}
+	}
+}
+
+func TestSetCanaryInfoMetric(t *testing.T) {
+	SetCanaryInfoMetric("openshift/origin-cluster-ingress-operator:latest")
+	if value := testutil.ToFloat64(CanaryInfo.WithLabelValues("openshift/origin-cluster-ingress-operator:latest")); value != 1 {
+		t.Errorf("expected the info series for the deployed image to be 1, but got %v", value)
+	}
+
+	// A new image replaces the previous image's series.
+	SetCanaryInfoMetric("registry.example.com/custom-canary:v2")
+	if count := testutil.CollectAndCount(CanaryInfo); count != 1 {
+		t.Errorf("expected 1 info series after an image change, but got %d", count)
+	}
+	if value := testutil.ToFloat64(CanaryInfo.WithLabelValues("registry.example.com/custom-canary:v2")); value != 1 {
+		t.Errorf("expected the info series for the new image to be 1, but got %v", value)
+	}
+}
+
+func TestMetricHostLabel(t *testing.T) {
+	testCases := []struct {
+		description string
+		stableLabel string
+		host        string
+		expected    string
+	}{
+		{
+			description: "no stable label configured",
+			stableLabel: "",
+			host:        "canary-openshift-ingress-canary.apps.example.com",
+			expected:    "canary-openshift-ingress-canary.apps.example.com",
+		},
+		{
+			description: "stable label configured",
+			stableLabel: "default",
+			host:        "canary-openshift-ingress-canary.apps.example.com",
+			expected:    "default",
+		},
+	}
+
+	for _, tc := range testCases {
+		r := &reconciler{
+			config: Config{
+				StableMetricHostLabel: tc.stableLabel,
+			},
+		}
+		if actual := r.metricHostLabel(tc.host); actual != tc.expected {
+			t.Errorf("%s: expected host label %q, but got %q", tc.description, tc.expected, actual)
+		}
+	}
+}
+
+func TestSetCanaryRequestTimeBuckets(t *testing.T) {
+	customBuckets := []float64{1, 5, 10}
+	SetCanaryRequestTimeBuckets(customBuckets)
+	defer SetCanaryRequestTimeBuckets(nil)
+
+	CanaryRequestTime.WithLabelValues("host").Observe(7)
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(CanaryRequestTime)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather canary metrics: %v", err)
+	}
+	if len(families) != 1 {
+		t.Fatalf("expected 1 metric family, but got %d", len(families))
+	}
+
+	buckets := families[0].GetMetric()[0].GetHistogram().GetBucket()
+	if len(buckets) != len(customBuckets) {
+		t.Fatalf("expected %d histogram buckets, but got %d", len(customBuckets), len(buckets))
+	}
+	for i, bucket := range buckets {
+		if bucket.GetUpperBound() != customBuckets[i] {
+			t.Errorf("expected bucket upper bound %v at index %d, but got %v", customBuckets[i], i, bucket.GetUpperBound())
+		}
+	}
+}
+
+// TestSetCanaryAvailableRotationPortsMetric verifies that the available
+// rotation ports gauge reflects the canary service's port count.
+func TestSetCanaryAvailableRotationPortsMetric(t *testing.T) {
+	testCases := []struct {
+		description string
+		service     *corev1.Service
+		expected    float64
+	}{
+		{
+			description: "service with no ports",
+			service:     &corev1.Service{},
+			expected:    0,
+		},
+		{
+			description: "service with one port",
+			service: &corev1.Service{
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{Port: 8080},
+					},
+				},
+			},
+			expected: 1,
+		},
+		{
+			description: "service with three ports",
+			service: &corev1.Service{
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{Port: 8080},
+						{Port: 8888},
+						{Port: 9090},
+					},
+				},
+			},
+			expected: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		SetCanaryAvailableRotationPortsMetric(len(tc.service.Spec.Ports))
+		if got := testutil.ToFloat64(CanaryAvailableRotationPorts); got != tc.expected {
+			t.Errorf("%s: expected the available rotation ports gauge to be %v, but got %v", tc.description, tc.expected, got)
+		}
+	}
+}
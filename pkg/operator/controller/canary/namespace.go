@@ -10,13 +10,46 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
-
-	projectv1 "github.com/openshift/api/project/v1"
 )
 
+// canaryNamespace returns the namespace that the canary resources are
+// managed in, preferring the configured namespace and falling back to
+// the default canary namespace.
+func (r *reconciler) canaryNamespace() string {
+	if len(r.config.CanaryNamespace) != 0 {
+		return r.config.CanaryNamespace
+	}
+	return controller.DefaultCanaryNamespace
+}
+
+// canaryDaemonSetName returns the canary daemonset's namespaced name
+// in the configured canary namespace.
+func (r *reconciler) canaryDaemonSetName() types.NamespacedName {
+	name := controller.CanaryDaemonSetName()
+	name.Namespace = r.canaryNamespace()
+	return name
+}
+
+// canaryServiceName returns the canary service's namespaced name in
+// the configured canary namespace.
+func (r *reconciler) canaryServiceName() types.NamespacedName {
+	name := controller.CanaryServiceName()
+	name.Namespace = r.canaryNamespace()
+	return name
+}
+
+// canaryRouteName returns the canary route's namespaced name in the
+// configured canary namespace.
+func (r *reconciler) canaryRouteName() types.NamespacedName {
+	name := controller.CanaryRouteName()
+	name.Namespace = r.canaryNamespace()
+	return name
+}
+
 // ensureCanaryNamespace ensures that the ingress-canary namespace exists
 func (r *reconciler) ensureCanaryNamespace() (bool, *corev1.Namespace, error) {
 	desired := manifests.CanaryNamespace()
+	desired.Name = r.canaryNamespace()
 
 	haveNamespace, current, err := r.currentCanaryNamespace()
 	if err != nil {
@@ -43,7 +76,7 @@ func (r *reconciler) ensureCanaryNamespace() (bool, *corev1.Namespace, error) {
 // currentCanaryNamespace gets the current canary namespace resource
 func (r *reconciler) currentCanaryNamespace() (bool, *corev1.Namespace, error) {
 	ns := &corev1.Namespace{}
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: controller.DefaultCanaryNamespace}, ns); err != nil {
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: r.canaryNamespace()}, ns); err != nil {
 		if errors.IsNotFound(err) {
 			return false, nil, nil
 		}
@@ -62,6 +95,20 @@ func (r *reconciler) createCanaryNamespace(ns *corev1.Namespace) error {
 	return nil
 }
 
+// deleteCanaryNamespace deletes the given namespace, tolerating an
+// already-deleted namespace
+func (r *reconciler) deleteCanaryNamespace(ctx context.Context, ns *corev1.Namespace) error {
+	if err := r.client.Delete(ctx, ns); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete canary namespace %s: %v", ns.Name, err)
+	}
+
+	log.Info("deleted canary namespace", "namespace", ns.Name)
+	return nil
+}
+
 // updateCanaryNamespace updates the canary namespace if an appropriate change
 // has been detected
 func (r *reconciler) updateCanaryNamespace(current, desired *corev1.Namespace) (bool, error) {
@@ -77,20 +124,39 @@ func (r *reconciler) updateCanaryNamespace(current, desired *corev1.Namespace) (
 	return true, nil
 }
 
-// canaryNamespaceChanged returns true if current and expected differ by the openshift
-// namespace node-selector annotation
+// canaryNamespaceChanged returns true if the current namespace is
+// missing any expected label or annotation, or carries a stale value
+// for one (e.g. the openshift.io/cluster-monitoring or pod-security
+// labels, or the openshift namespace node-selector annotation), along
+// with the updated namespace. Extra labels and annotations on the
+// current namespace are left alone.
 func canaryNamespaceChanged(current, expected *corev1.Namespace) (bool, *corev1.Namespace) {
+	changed := false
 	updated := current.DeepCopy()
 
-	if updated.Annotations == nil {
-		updated.Annotations = map[string]string{}
+	for key, value := range expected.Labels {
+		if updated.Labels[key] != value {
+			if updated.Labels == nil {
+				updated.Labels = map[string]string{}
+			}
+			updated.Labels[key] = value
+			changed = true
+		}
 	}
 
-	if current.Annotations[projectv1.ProjectNodeSelector] == expected.Annotations[projectv1.ProjectNodeSelector] {
-		return false, nil
+	for key, value := range expected.Annotations {
+		if updated.Annotations[key] != value {
+			if updated.Annotations == nil {
+				updated.Annotations = map[string]string{}
+			}
+			updated.Annotations[key] = value
+			changed = true
+		}
 	}
 
-	updated.Annotations[projectv1.ProjectNodeSelector] = expected.Annotations[projectv1.ProjectNodeSelector]
+	if !changed {
+		return false, nil
+	}
 
 	return true, updated
 }
@@ -1,15 +1,77 @@
 package canary
 
 import (
+	"context"
 	"testing"
 
 	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	projectv1 "github.com/openshift/api/project/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
+func TestCanaryNamespaceOverride(t *testing.T) {
+	overrideNamespace := "custom-ingress-canary"
+	r := &reconciler{
+		client: fake.NewClientBuilder().WithScheme(operatorclient.GetScheme()).Build(),
+		config: Config{
+			CanaryImage:     "openshift/origin-cluster-ingress-operator:latest",
+			CanaryNamespace: overrideNamespace,
+		},
+	}
+
+	haveNs, ns, err := r.ensureCanaryNamespace()
+	if err != nil {
+		t.Fatalf("ensureCanaryNamespace returned an error: %v", err)
+	} else if !haveNs {
+		t.Fatal("expected ensureCanaryNamespace to return a namespace")
+	}
+	if ns.Name != overrideNamespace {
+		t.Errorf("expected canary namespace to be %q, but got %q", overrideNamespace, ns.Name)
+	}
+
+	haveDs, daemonset, err := r.ensureCanaryDaemonSet(context.TODO())
+	if err != nil {
+		t.Fatalf("ensureCanaryDaemonSet returned an error: %v", err)
+	} else if !haveDs {
+		t.Fatal("expected ensureCanaryDaemonSet to return a daemonset")
+	}
+	if daemonset.Namespace != overrideNamespace {
+		t.Errorf("expected canary daemonset namespace to be %q, but got %q", overrideNamespace, daemonset.Namespace)
+	}
+
+	daemonsetRef := metav1.OwnerReference{
+		APIVersion: "apps/v1",
+		Kind:       "daemonset",
+		Name:       daemonset.Name,
+		UID:        daemonset.UID,
+	}
+	haveService, service, err := r.ensureCanaryService(context.TODO(), daemonsetRef)
+	if err != nil {
+		t.Fatalf("ensureCanaryService returned an error: %v", err)
+	} else if !haveService {
+		t.Fatal("expected ensureCanaryService to return a service")
+	}
+	if service.Namespace != overrideNamespace {
+		t.Errorf("expected canary service namespace to be %q, but got %q", overrideNamespace, service.Namespace)
+	}
+
+	haveRoute, route, err := r.ensureCanaryRoute(context.TODO(), service)
+	if err != nil {
+		t.Fatalf("ensureCanaryRoute returned an error: %v", err)
+	} else if !haveRoute {
+		t.Fatal("expected ensureCanaryRoute to return a route")
+	}
+	if route.Namespace != overrideNamespace {
+		t.Errorf("expected canary route namespace to be %q, but got %q", overrideNamespace, route.Namespace)
+	}
+}
+
 func TestCanaryNamespaceChanged(t *testing.T) {
 	testCases := []struct {
 		description string
@@ -28,6 +90,16 @@ func TestCanaryNamespaceChanged(t *testing.T) {
 			},
 			expect: true,
 		},
+		{
+			description: "if an expected label is added",
+			mutate: func(ns *corev1.Namespace) {
+				if ns.Labels == nil {
+					ns.Labels = map[string]string{}
+				}
+				ns.Labels["openshift.io/cluster-monitoring"] = "true"
+			},
+			expect: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -43,3 +115,32 @@ func TestCanaryNamespaceChanged(t *testing.T) {
 		}
 	}
 }
+
+// TestCanaryNamespaceMissingLabelsApplied verifies that a namespace
+// missing the labels the canary needs gets them applied while
+// unrelated labels are preserved.
+func TestCanaryNamespaceMissingLabelsApplied(t *testing.T) {
+	expected := manifests.CanaryNamespace()
+	expected.Labels = map[string]string{
+		"openshift.io/cluster-monitoring":    "true",
+		"pod-security.kubernetes.io/enforce": "restricted",
+	}
+
+	current := manifests.CanaryNamespace()
+	current.Labels = map[string]string{
+		"unrelated": "label",
+	}
+
+	changed, updated := canaryNamespaceChanged(current, expected)
+	if !changed {
+		t.Fatalf("expected canaryNamespaceChanged to report a change for a namespace missing the required labels")
+	}
+	for key, value := range expected.Labels {
+		if updated.Labels[key] != value {
+			t.Errorf("expected label %q to be %q, but got %q", key, value, updated.Labels[key])
+		}
+	}
+	if updated.Labels["unrelated"] != "label" {
+		t.Errorf("expected the unrelated label to be preserved, but got %q", updated.Labels["unrelated"])
+	}
+}
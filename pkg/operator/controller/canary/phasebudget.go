@@ -0,0 +1,81 @@
+package canary
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tcnksm/go-httpstat"
+)
+
+// ProbePhase identifies a phase of a canary probe request.
+type ProbePhase string
+
+const (
+	// ProbePhaseDNS is the DNS lookup phase.
+	ProbePhaseDNS ProbePhase = "dns"
+	// ProbePhaseConnect is the TCP connection phase.
+	ProbePhaseConnect ProbePhase = "connect"
+	// ProbePhaseTLS is the TLS handshake phase.
+	ProbePhaseTLS ProbePhase = "tls"
+	// ProbePhaseTTFB is the server processing phase, from the end of
+	// the request write to the first response byte.
+	ProbePhaseTTFB ProbePhase = "ttfb"
+	// ProbePhaseBodyRead is the response body transfer phase.
+	ProbePhaseBodyRead ProbePhase = "body-read"
+)
+
+// PhaseBudgets holds per-phase timing budgets for a canary probe. A
+// zero-valued field means that phase has no individual budget; the
+// overall probe timeout still applies.
+type PhaseBudgets struct {
+	// DNS is the budget for the DNS lookup phase.
+	DNS time.Duration
+	// Connect is the budget for the TCP connection phase.
+	Connect time.Duration
+	// TLS is the budget for the TLS handshake phase.
+	TLS time.Duration
+	// TTFB is the budget for the server processing phase.
+	TTFB time.Duration
+	// BodyRead is the budget for the response body transfer phase.
+	BodyRead time.Duration
+}
+
+// PhaseBudgetError reports that a single probe phase exceeded its
+// configured budget, attributing a slow probe to a specific phase
+// rather than to a single overall timeout.
+type PhaseBudgetError struct {
+	// Phase is the phase that exceeded its budget.
+	Phase ProbePhase
+	// Elapsed is how long the phase took.
+	Elapsed time.Duration
+	// Budget is the phase's configured budget.
+	Budget time.Duration
+}
+
+func (e *PhaseBudgetError) Error() string {
+	return fmt.Sprintf("canary probe %s phase took %v, exceeding the %v budget", e.Phase, e.Elapsed, e.Budget)
+}
+
+// checkPhaseBudgets evaluates the measured per-phase durations of a
+// completed probe against the given budgets and returns a
+// PhaseBudgetError for the first phase that exceeded its budget, in
+// request order.
+func checkPhaseBudgets(result *httpstat.Result, bodyRead time.Duration, budgets PhaseBudgets) error {
+	phases := []struct {
+		phase   ProbePhase
+		elapsed time.Duration
+		budget  time.Duration
+	}{
+		{ProbePhaseDNS, result.DNSLookup, budgets.DNS},
+		{ProbePhaseConnect, result.TCPConnection, budgets.Connect},
+		{ProbePhaseTLS, result.TLSHandshake, budgets.TLS},
+		{ProbePhaseTTFB, result.ServerProcessing, budgets.TTFB},
+		{ProbePhaseBodyRead, bodyRead, budgets.BodyRead},
+	}
+	for _, p := range phases {
+		if p.budget != 0 && p.elapsed > p.budget {
+			return &PhaseBudgetError{Phase: p.phase, Elapsed: p.elapsed, Budget: p.budget}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package canary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tcnksm/go-httpstat"
+)
+
+func TestCheckPhaseBudgets(t *testing.T) {
+	measured := &httpstat.Result{
+		DNSLookup:        10 * time.Millisecond,
+		TCPConnection:    20 * time.Millisecond,
+		TLSHandshake:     30 * time.Millisecond,
+		ServerProcessing: 40 * time.Millisecond,
+	}
+	bodyRead := 50 * time.Millisecond
+
+	testCases := []struct {
+		description   string
+		budgets       PhaseBudgets
+		expectedPhase ProbePhase
+	}{
+		{
+			description:   "no budgets configured",
+			budgets:       PhaseBudgets{},
+			expectedPhase: "",
+		},
+		{
+			description: "all phases within budget",
+			budgets: PhaseBudgets{
+				DNS:      time.Second,
+				Connect:  time.Second,
+				TLS:      time.Second,
+				TTFB:     time.Second,
+				BodyRead: time.Second,
+			},
+			expectedPhase: "",
+		},
+		{
+			description:   "DNS budget exceeded",
+			budgets:       PhaseBudgets{DNS: time.Millisecond},
+			expectedPhase: ProbePhaseDNS,
+		},
+		{
+			description:   "connect budget exceeded",
+			budgets:       PhaseBudgets{Connect: time.Millisecond},
+			expectedPhase: ProbePhaseConnect,
+		},
+		{
+			description:   "TLS budget exceeded",
+			budgets:       PhaseBudgets{TLS: time.Millisecond},
+			expectedPhase: ProbePhaseTLS,
+		},
+		{
+			description:   "TTFB budget exceeded",
+			budgets:       PhaseBudgets{TTFB: time.Millisecond},
+			expectedPhase: ProbePhaseTTFB,
+		},
+		{
+			description:   "body-read budget exceeded",
+			budgets:       PhaseBudgets{BodyRead: time.Millisecond},
+			expectedPhase: ProbePhaseBodyRead,
+		},
+		{
+			description: "earliest exceeded phase wins",
+			budgets: PhaseBudgets{
+				Connect: time.Millisecond,
+				TTFB:    time.Millisecond,
+			},
+			expectedPhase: ProbePhaseConnect,
+		},
+	}
+
+	for _, tc := range testCases {
+		err := checkPhaseBudgets(measured, bodyRead, tc.budgets)
+		if len(tc.expectedPhase) == 0 {
+			if err != nil {
+				t.Errorf("%s: checkPhaseBudgets returned an unexpected error: %v", tc.description, err)
+			}
+			continue
+		}
+		budgetErr := &PhaseBudgetError{}
+		if !errors.As(err, &budgetErr) {
+			t.Errorf("%s: expected a PhaseBudgetError, but got %v", tc.description, err)
+		} else if budgetErr.Phase != tc.expectedPhase {
+			t.Errorf("%s: expected phase %q to exceed its budget, but got %q", tc.description, tc.expectedPhase, budgetErr.Phase)
+		}
+	}
+}
+
+func TestProbeRouteEndpointPhaseBudgets(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// Stall before writing the response to inflate the server
+		// processing (TTFB) phase.
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Set(echoServerPortAckHeader, "8080")
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	defer server.Close()
+
+	route := routeForServer(server, "8080")
+	r := &reconciler{
+		config: Config{
+			PhaseBudgets: PhaseBudgets{
+				TTFB: time.Millisecond,
+			},
+		},
+	}
+
+	_, err := r.probeRouteEndpoint(context.TODO(), route)
+	budgetErr := &PhaseBudgetError{}
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("expected probeRouteEndpoint to return a PhaseBudgetError, but got %v", err)
+	}
+	if budgetErr.Phase != ProbePhaseTTFB {
+		t.Errorf("expected the %q phase to exceed its budget, but got %q", ProbePhaseTTFB, budgetErr.Phase)
+	}
+}
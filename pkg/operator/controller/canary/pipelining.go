@@ -0,0 +1,69 @@
+package canary
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+// probePipelining opens a raw connection to the route's endpoint and
+// writes a pipelined pair of requests, verifying that the router
+// handles pipelining safely — either serializing both responses or
+// closing the connection — rather than corrupting the response stream.
+func (r *reconciler) probePipelining(route *routev1.Route) error {
+	conn, err := r.dialRouteProbeConn(route)
+	if err != nil {
+		return fmt.Errorf("error dialing %q for canary pipelining probe: %v", route.Spec.Host, err)
+	}
+	defer conn.Close()
+
+	options := r.probeOptionsForHost(route.Spec.Host)
+	return checkPipelinedResponses(conn, route.Spec.Host, options.Timeout)
+}
+
+// checkPipelinedResponses writes two pipelined requests over the given
+// connection and validates the response stream. A cleanly closed
+// connection is acceptable; a response stream that fails to parse
+// indicates corruption and returns an error.
+func checkPipelinedResponses(conn net.Conn, host string, timeout time.Duration) error {
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return fmt.Errorf("error setting deadline for canary pipelining probe: %v", err)
+	}
+
+	request := fmt.Sprintf("GET / HTTP/1.1\r\nHost: %s\r\n\r\n", host)
+	if _, err := conn.Write([]byte(request + request)); err != nil {
+		return fmt.Errorf("error writing pipelined canary requests: %v", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	for i := 0; i < 2; i++ {
+		response, err := http.ReadResponse(reader, nil)
+		if err != nil {
+			// Closing the connection instead of answering the
+			// pipelined requests is a safe rejection.
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil
+			}
+			return fmt.Errorf("pipelined canary response %d is corrupt: %v", i+1, err)
+		}
+		// Drain the body so that the reader is positioned at the
+		// next response.
+		if _, err := io.Copy(ioutil.Discard, response.Body); err != nil {
+			response.Body.Close()
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil
+			}
+			return fmt.Errorf("error reading pipelined canary response %d body: %v", i+1, err)
+		}
+		response.Body.Close()
+	}
+
+	return nil
+}
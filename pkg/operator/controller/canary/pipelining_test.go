@@ -0,0 +1,91 @@
+package canary
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckPipelinedResponses(t *testing.T) {
+	t.Run("server that serializes pipelined responses passes", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			fmt.Fprint(w, CanaryHealthcheckResponse)
+		}))
+		defer server.Close()
+
+		conn, err := net.Dial("tcp", server.Listener.Addr().String())
+		if err != nil {
+			t.Fatalf("failed to dial test server: %v", err)
+		}
+		defer conn.Close()
+
+		if err := checkPipelinedResponses(conn, "canary.example.com", 5*time.Second); err != nil {
+			t.Errorf("expected pipelining probe to pass against a serializing server, but got: %v", err)
+		}
+	})
+
+	t.Run("server that closes the connection passes", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("failed to create listener: %v", err)
+		}
+		defer listener.Close()
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				// Close without responding, a safe rejection.
+				conn.Close()
+			}
+		}()
+
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		if err != nil {
+			t.Fatalf("failed to dial test listener: %v", err)
+		}
+		defer conn.Close()
+
+		if err := checkPipelinedResponses(conn, "canary.example.com", 5*time.Second); err != nil {
+			t.Errorf("expected pipelining probe to pass against a server that closes cleanly, but got: %v", err)
+		}
+	})
+
+	t.Run("server that corrupts the response stream fails", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("failed to create listener: %v", err)
+		}
+		defer listener.Close()
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				go func(conn net.Conn) {
+					defer conn.Close()
+					buffer := make([]byte, 1024)
+					conn.Read(buffer)
+					// Respond with bytes that do not parse as
+					// an HTTP response.
+					conn.Write([]byte("garbage response\r\n\r\n"))
+				}(conn)
+			}
+		}()
+
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		if err != nil {
+			t.Fatalf("failed to dial test listener: %v", err)
+		}
+		defer conn.Close()
+
+		if err := checkPipelinedResponses(conn, "canary.example.com", 5*time.Second); err == nil {
+			t.Error("expected pipelining probe to fail against a server that corrupts responses, but it passed")
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package canary
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// probeEvent is the JSON structure emitted for each probe outcome when
+// a probe event writer is configured. Field names are stable so that
+// JSON-based log pipelines can rely on them independent of the
+// operator's logger backend.
+type probeEvent struct {
+	// Time is when the probe completed, in RFC 3339 format.
+	Time string `json:"time"`
+	// Host is the host that was probed.
+	Host string `json:"host"`
+	// Success indicates whether the probe succeeded.
+	Success bool `json:"success"`
+	// Error is the probe's error message, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// emitProbeEvent writes a structured JSON line describing the given
+// probe outcome to the configured probe event writer, if any.
+func (r *reconciler) emitProbeEvent(host string, err error) {
+	if r.config.ProbeEventWriter == nil {
+		return
+	}
+
+	event := probeEvent{
+		Time:    time.Now().Format(time.RFC3339),
+		Host:    host,
+		Success: err == nil,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+
+	line, marshalErr := json.Marshal(event)
+	if marshalErr != nil {
+		log.Error(marshalErr, "error marshaling canary probe event")
+		return
+	}
+	line = append(line, '\n')
+	if _, writeErr := r.config.ProbeEventWriter.Write(line); writeErr != nil {
+		log.Error(writeErr, "error writing canary probe event")
+	}
+}
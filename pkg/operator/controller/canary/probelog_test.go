@@ -0,0 +1,41 @@
+package canary
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestEmitProbeEvent(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	r := &reconciler{
+		config: Config{
+			ProbeEventWriter: buffer,
+		},
+	}
+
+	r.emitProbeEvent("canary.example.com", nil)
+	r.emitProbeEvent("canary.example.com", errors.New("probe failed"))
+
+	lines := bytes.Split(bytes.TrimSpace(buffer.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 probe event lines, but got %d", len(lines))
+	}
+
+	var success probeEvent
+	if err := json.Unmarshal(lines[0], &success); err != nil {
+		t.Fatalf("expected probe event to be valid JSON: %v", err)
+	}
+	if success.Host != "canary.example.com" || !success.Success || len(success.Error) != 0 || len(success.Time) == 0 {
+		t.Errorf("unexpected successful probe event: %+v", success)
+	}
+
+	var failure probeEvent
+	if err := json.Unmarshal(lines[1], &failure); err != nil {
+		t.Fatalf("expected probe event to be valid JSON: %v", err)
+	}
+	if failure.Success || failure.Error != "probe failed" {
+		t.Errorf("unexpected failed probe event: %+v", failure)
+	}
+}
@@ -0,0 +1,90 @@
+package canary
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// probeProxy returns the proxy selection function for probe
+// transports. The cluster-wide proxy configuration is preferred so
+// that probes traverse the same path as real traffic; when no cluster
+// proxy is configured, or it cannot be read, the pod's environment
+// proxy settings are used.
+func (r *reconciler) probeProxy() func(*http.Request) (*url.URL, error) {
+	if r.client == nil {
+		return http.ProxyFromEnvironment
+	}
+	proxy, err := r.currentClusterProxy()
+	if err != nil {
+		log.Error(err, "failed to get cluster proxy config for canary probe, falling back to the environment proxy settings")
+		return http.ProxyFromEnvironment
+	}
+	if proxyFunc := probeProxyFunc(proxy); proxyFunc != nil {
+		return proxyFunc
+	}
+	return http.ProxyFromEnvironment
+}
+
+// probeProxyFunc returns a proxy selection function honoring the given
+// cluster proxy's resolved settings, including noProxy matching, or
+// nil when the proxy config carries no resolved proxy URLs.
+func probeProxyFunc(proxy *configv1.Proxy) func(*http.Request) (*url.URL, error) {
+	if proxy == nil {
+		return nil
+	}
+	httpProxy := proxy.Status.HTTPProxy
+	httpsProxy := proxy.Status.HTTPSProxy
+	noProxy := proxy.Status.NoProxy
+	if len(httpProxy) == 0 && len(httpsProxy) == 0 {
+		return nil
+	}
+
+	return func(request *http.Request) (*url.URL, error) {
+		if noProxyMatches(request.URL.Hostname(), noProxy) {
+			return nil, nil
+		}
+		proxyURL := httpProxy
+		if request.URL.Scheme == "https" {
+			proxyURL = httpsProxy
+		}
+		if len(proxyURL) == 0 {
+			return nil, nil
+		}
+		return url.Parse(proxyURL)
+	}
+}
+
+// noProxyMatches returns true if the given host matches an entry of
+// the given comma-separated noProxy list. An entry matches the host
+// exactly, as a domain suffix (with or without a leading dot), or as a
+// CIDR containing the host's IP.
+func noProxyMatches(host, noProxy string) bool {
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+		if entry == "*" || entry == host {
+			return true
+		}
+		if strings.HasPrefix(entry, ".") {
+			if strings.HasSuffix(host, entry) {
+				return true
+			}
+			continue
+		}
+		if strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip := net.ParseIP(host); ip != nil && cidr.Contains(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
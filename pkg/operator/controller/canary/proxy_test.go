@@ -0,0 +1,94 @@
+package canary
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func TestProbeProxyFunc(t *testing.T) {
+	proxy := &configv1.Proxy{
+		Status: configv1.ProxyStatus{
+			HTTPProxy:  "http://proxy.example.com:3128",
+			HTTPSProxy: "https://proxy.example.com:3128",
+			NoProxy:    ".cluster.local,.svc,apps.example.com,10.0.0.0/16",
+		},
+	}
+
+	testCases := []struct {
+		description      string
+		proxy            *configv1.Proxy
+		requestURL       string
+		expectedProxyURL string
+	}{
+		{
+			description:      "https request to a proxied host",
+			proxy:            proxy,
+			requestURL:       "https://canary.external.example.org/",
+			expectedProxyURL: "https://proxy.example.com:3128",
+		},
+		{
+			description:      "http request to a proxied host",
+			proxy:            proxy,
+			requestURL:       "http://canary.external.example.org/",
+			expectedProxyURL: "http://proxy.example.com:3128",
+		},
+		{
+			description:      "host matching a noProxy domain suffix",
+			proxy:            proxy,
+			requestURL:       "https://canary-openshift-ingress-canary.apps.example.com/",
+			expectedProxyURL: "",
+		},
+		{
+			description:      "host matching a noProxy leading-dot suffix",
+			proxy:            proxy,
+			requestURL:       "https://service.svc/",
+			expectedProxyURL: "",
+		},
+		{
+			description:      "IP matching a noProxy CIDR",
+			proxy:            proxy,
+			requestURL:       "https://10.0.12.7/",
+			expectedProxyURL: "",
+		},
+		{
+			description: "proxy config without resolved proxy URLs",
+			proxy:       &configv1.Proxy{},
+			requestURL:  "https://canary.external.example.org/",
+		},
+		{
+			description: "no proxy config",
+			proxy:       nil,
+			requestURL:  "https://canary.external.example.org/",
+		},
+	}
+
+	for _, tc := range testCases {
+		proxyFunc := probeProxyFunc(tc.proxy)
+		if proxyFunc == nil {
+			if tc.proxy != nil && len(tc.proxy.Status.HTTPProxy)+len(tc.proxy.Status.HTTPSProxy) != 0 {
+				t.Errorf("%s: expected a proxy function, but got nil", tc.description)
+			}
+			continue
+		}
+
+		requestURL, err := url.Parse(tc.requestURL)
+		if err != nil {
+			t.Fatalf("%s: failed to parse request URL: %v", tc.description, err)
+		}
+		proxyURL, err := proxyFunc(&http.Request{URL: requestURL})
+		if err != nil {
+			t.Fatalf("%s: proxy function returned an error: %v", tc.description, err)
+		}
+
+		gotProxyURL := ""
+		if proxyURL != nil {
+			gotProxyURL = proxyURL.String()
+		}
+		if gotProxyURL != tc.expectedProxyURL {
+			t.Errorf("%s: expected proxy URL %q, but got %q", tc.description, tc.expectedProxyURL, gotProxyURL)
+		}
+	}
+}
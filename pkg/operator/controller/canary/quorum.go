@@ -0,0 +1,35 @@
+package canary
+
+// meetsProbeQuorum returns true if the given number of successful
+// probes out of the total meets the required quorum fraction. A zero
+// fraction requires every probe to succeed, preserving the
+// all-or-nothing default.
+func meetsProbeQuorum(successes, total int, fraction float64) bool {
+	if total == 0 {
+		return true
+	}
+	if fraction == 0 {
+		fraction = 1.0
+	}
+	return float64(successes) >= fraction*float64(total)
+}
+
+// probeSetReachable probes the configured extra targets synchronously
+// and returns whether the aggregate probe set, including the canary
+// route probe's outcome, meets the configured quorum. Per-probe
+// failures are still logged individually.
+func (r *reconciler) probeSetReachable(routeProbeSucceeded bool) bool {
+	successes := 0
+	total := 1 + len(r.config.ExtraProbeTargets)
+	if routeProbeSucceeded {
+		successes++
+	}
+	for _, target := range r.config.ExtraProbeTargets {
+		if err := r.probeExtraTarget(target); err != nil {
+			log.Error(err, "error performing extra canary target check", "target", target.URL)
+		} else {
+			successes++
+		}
+	}
+	return meetsProbeQuorum(successes, total, r.config.ProbeQuorumFraction)
+}
@@ -0,0 +1,122 @@
+package canary
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMeetsProbeQuorum(t *testing.T) {
+	testCases := []struct {
+		description string
+		successes   int
+		total       int
+		fraction    float64
+		expect      bool
+	}{
+		{
+			description: "zero fraction requires all probes to succeed",
+			successes:   4,
+			total:       5,
+			fraction:    0,
+			expect:      false,
+		},
+		{
+			description: "zero fraction with all probes succeeding",
+			successes:   5,
+			total:       5,
+			fraction:    0,
+			expect:      true,
+		},
+		{
+			description: "quorum met exactly",
+			successes:   4,
+			total:       5,
+			fraction:    0.8,
+			expect:      true,
+		},
+		{
+			description: "quorum not met",
+			successes:   3,
+			total:       5,
+			fraction:    0.8,
+			expect:      false,
+		},
+		{
+			description: "empty probe set",
+			successes:   0,
+			total:       0,
+			fraction:    0.8,
+			expect:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := meetsProbeQuorum(tc.successes, tc.total, tc.fraction); got != tc.expect {
+			t.Errorf("%s: expected meetsProbeQuorum to be %t, but got %t", tc.description, tc.expect, got)
+		}
+	}
+}
+
+func TestProbeSetReachable(t *testing.T) {
+	healthy := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	defer healthy.Close()
+	failing := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	testCases := []struct {
+		description         string
+		routeProbeSucceeded bool
+		extraTargets        []ProbeTarget
+		fraction            float64
+		expect              bool
+	}{
+		{
+			description:         "two of three passing meets a 0.6 quorum",
+			routeProbeSucceeded: true,
+			extraTargets: []ProbeTarget{
+				{URL: healthy.URL, InsecureSkipVerify: true},
+				{URL: failing.URL, InsecureSkipVerify: true},
+			},
+			fraction: 0.6,
+			expect:   true,
+		},
+		{
+			description:         "one of three passing misses a 0.6 quorum",
+			routeProbeSucceeded: false,
+			extraTargets: []ProbeTarget{
+				{URL: healthy.URL, InsecureSkipVerify: true},
+				{URL: failing.URL, InsecureSkipVerify: true},
+			},
+			fraction: 0.6,
+			expect:   false,
+		},
+		{
+			description:         "failed route probe alone does not break a low quorum",
+			routeProbeSucceeded: false,
+			extraTargets: []ProbeTarget{
+				{URL: healthy.URL, InsecureSkipVerify: true},
+				{URL: healthy.URL, InsecureSkipVerify: true},
+			},
+			fraction: 0.6,
+			expect:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		r := &reconciler{
+			config: Config{
+				ExtraProbeTargets:   tc.extraTargets,
+				ProbeQuorumFraction: tc.fraction,
+			},
+		}
+		if got := r.probeSetReachable(tc.routeProbeSucceeded); got != tc.expect {
+			t.Errorf("%s: expected probeSetReachable to be %t, but got %t", tc.description, tc.expect, got)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package canary
+
+import (
+	"context"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// defaultMaxProbeRetries is how many attempts a canary check makes
+	// before declaring the route unreachable when
+	// Config.MaxProbeRetries is unset.
+	defaultMaxProbeRetries = 3
+	// probeRetryBaseDelay is the delay before the first probe retry;
+	// the delay doubles after each failed attempt.
+	probeRetryBaseDelay = 1 * time.Second
+
+	// defaultMaxCreateRetries is how many attempts a canary resource
+	// creation makes within a single reconcile when
+	// Config.MaxCreateRetries is unset.
+	defaultMaxCreateRetries = 3
+	// defaultCreateRetryBaseDelay is the delay before the first
+	// creation retry when Config.CreateRetryBaseDelay is unset; the
+	// delay doubles after each failed attempt.
+	defaultCreateRetryBaseDelay = 200 * time.Millisecond
+)
+
+// probeRouteEndpointWithRetries probes the given route, retrying with
+// exponential backoff up to the configured number of attempts so that
+// brief router reloads or transient DNS hiccups do not immediately
+// flip the route to unreachable. The last attempt's result and error
+// are returned, preserving the distinguishable DNS-error and
+// status-code branches of probeRouteEndpoint. Retries stop as soon as
+// the given context is canceled so that backoff sleeps do not hold up
+// a shutting-down polling loop.
+func (r *reconciler) probeRouteEndpointWithRetries(ctx context.Context, route *routev1.Route, baseDelay time.Duration) (*ProbeResult, error) {
+	attempts := r.config.MaxProbeRetries
+	if attempts == 0 {
+		attempts = defaultMaxProbeRetries
+	}
+
+	var probeResult *ProbeResult
+	var err error
+	delay := baseDelay
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return probeResult, err
+			case <-timer.C:
+			}
+			delay *= 2
+		}
+		CanaryProbeAttempts.Inc()
+		probeResult, err = r.probeRouteEndpoint(ctx, route)
+		if err == nil {
+			CanaryProbeSuccesses.Inc()
+			return probeResult, nil
+		}
+		CanaryProbeFailures.Inc()
+		// Retrying after the context is canceled would only burn
+		// attempts and backoff sleeps during shutdown.
+		if ctx.Err() != nil {
+			return probeResult, err
+		}
+		if attempt < attempts {
+			log.Info("canary probe attempt failed, retrying", "attempt", attempt, "maxAttempts", attempts, "error", err)
+		}
+	}
+
+	return probeResult, err
+}
+
+// createRetriable returns true for transient API errors that are worth
+// retrying a resource creation for within a single reconcile.
+func createRetriable(err error) bool {
+	return apierrors.IsServerTimeout(err) || apierrors.IsConflict(err) || apierrors.IsInternalError(err)
+}
+
+// retryCreate invokes create, retrying transient failures with
+// exponential backoff up to the configured number of attempts, so that
+// a busy API server does not leave the canary half-provisioned until
+// the next reconcile. Non-transient errors are returned immediately.
+func (r *reconciler) retryCreate(create func() error) error {
+	attempts := r.config.MaxCreateRetries
+	if attempts == 0 {
+		attempts = defaultMaxCreateRetries
+	}
+	delay := r.config.CreateRetryBaseDelay
+	if delay == 0 {
+		delay = defaultCreateRetryBaseDelay
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+		err = create()
+		if err == nil || !createRetriable(err) {
+			return err
+		}
+		if attempt < attempts {
+			log.Info("canary resource creation failed transiently, retrying", "attempt", attempt, "maxAttempts", attempts, "error", err)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,259 @@
+package canary
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestProbeRouteEndpointWithRetries(t *testing.T) {
+	testCases := []struct {
+		description     string
+		failures        int32
+		maxRetries      int
+		expectError     bool
+		expectedAttempt int32
+	}{
+		{
+			description:     "transient failure recovers within the retry budget",
+			failures:        2,
+			maxRetries:      3,
+			expectError:     false,
+			expectedAttempt: 3,
+		},
+		{
+			description:     "persistent failure exhausts the retry budget",
+			failures:        10,
+			maxRetries:      3,
+			expectError:     true,
+			expectedAttempt: 3,
+		},
+		{
+			description:     "zero retries falls back to the default",
+			failures:        defaultMaxProbeRetries - 1,
+			maxRetries:      0,
+			expectError:     false,
+			expectedAttempt: defaultMaxProbeRetries,
+		},
+	}
+
+	for _, tc := range testCases {
+		var attempts int32
+		failures := tc.failures
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			attempt := atomic.AddInt32(&attempts, 1)
+			if attempt <= failures {
+				w.Header().Set(echoServerPortAckHeader, "8080")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				fmt.Fprint(w, CanaryHealthcheckResponse)
+				return
+			}
+			w.Header().Set(echoServerPortAckHeader, "8080")
+			fmt.Fprint(w, CanaryHealthcheckResponse)
+		}))
+
+		r := &reconciler{
+			config: Config{
+				MaxProbeRetries: tc.maxRetries,
+			},
+		}
+
+		route := routeForServer(server, "8080")
+		_, err := r.probeRouteEndpointWithRetries(context.TODO(), route, time.Millisecond)
+		if tc.expectError && err == nil {
+			t.Errorf("%s: expected an error, but got none", tc.description)
+		} else if !tc.expectError && err != nil {
+			t.Errorf("%s: expected no error, but got: %v", tc.description, err)
+		}
+		if got := atomic.LoadInt32(&attempts); got != tc.expectedAttempt {
+			t.Errorf("%s: expected %d probe attempts, but got %d", tc.description, tc.expectedAttempt, got)
+		}
+
+		// The final error must still carry the distinguishable
+		// status-code branch from the last attempt.
+		if tc.expectError && !strings.Contains(err.Error(), "status code 503") {
+			t.Errorf("%s: expected the final error to carry the status code, but got: %v", tc.description, err)
+		}
+
+		server.Close()
+	}
+}
+
+// TestProbeRouteEndpointWithRetriesCanceledContext verifies that a
+// canceled context stops the retry loop immediately rather than
+// burning the remaining attempts and backoff sleeps during shutdown.
+func TestProbeRouteEndpointWithRetriesCanceledContext(t *testing.T) {
+	var attempts int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set(echoServerPortAckHeader, "8080")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	defer server.Close()
+
+	r := &reconciler{
+		config: Config{
+			MaxProbeRetries: 3,
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	route := routeForServer(server, "8080")
+	start := time.Now()
+	_, err := r.probeRouteEndpointWithRetries(ctx, route, 10*time.Second)
+	if err == nil {
+		t.Fatal("expected an error for a probe with a canceled context, but got none")
+	}
+	if got := atomic.LoadInt32(&attempts); got > 1 {
+		t.Errorf("expected at most 1 probe attempt with a canceled context, but got %d", got)
+	}
+	if elapsed := time.Since(start); elapsed >= 10*time.Second {
+		t.Errorf("expected the retry loop to return without sleeping, but it took %v", elapsed)
+	}
+}
+
+func TestRetryCreate(t *testing.T) {
+	transient := apierrors.NewServerTimeout(schema.GroupResource{Resource: "routes"}, "create", 1)
+	terminal := apierrors.NewBadRequest("the route is invalid")
+
+	testCases := []struct {
+		description   string
+		failures      int
+		failWith      error
+		maxRetries    int
+		expectError   bool
+		expectedCalls int
+	}{
+		{
+			description:   "first attempt succeeds",
+			failures:      0,
+			maxRetries:    3,
+			expectError:   false,
+			expectedCalls: 1,
+		},
+		{
+			description:   "transient failures recover within the retry budget",
+			failures:      2,
+			failWith:      transient,
+			maxRetries:    3,
+			expectError:   false,
+			expectedCalls: 3,
+		},
+		{
+			description:   "persistent transient failures exhaust the retry budget",
+			failures:      10,
+			failWith:      transient,
+			maxRetries:    3,
+			expectError:   true,
+			expectedCalls: 3,
+		},
+		{
+			description:   "non-transient failure is not retried",
+			failures:      10,
+			failWith:      terminal,
+			maxRetries:    3,
+			expectError:   true,
+			expectedCalls: 1,
+		},
+		{
+			description:   "zero retries falls back to the default",
+			failures:      defaultMaxCreateRetries - 1,
+			failWith:      transient,
+			maxRetries:    0,
+			expectError:   false,
+			expectedCalls: defaultMaxCreateRetries,
+		},
+	}
+
+	for _, tc := range testCases {
+		r := &reconciler{
+			config: Config{
+				MaxCreateRetries:     tc.maxRetries,
+				CreateRetryBaseDelay: time.Millisecond,
+			},
+		}
+
+		calls := 0
+		err := r.retryCreate(func() error {
+			calls++
+			if calls <= tc.failures {
+				return tc.failWith
+			}
+			return nil
+		})
+		if tc.expectError && err == nil {
+			t.Errorf("%s: expected an error, but got none", tc.description)
+		} else if !tc.expectError && err != nil {
+			t.Errorf("%s: expected no error, but got: %v", tc.description, err)
+		}
+		if calls != tc.expectedCalls {
+			t.Errorf("%s: expected %d create attempts, but got %d", tc.description, tc.expectedCalls, calls)
+		}
+	}
+}
+
+// TestProbeCounters verifies that the probe attempt, success, and
+// failure counters move on the corresponding probe outcomes, including
+// retried attempts.
+func TestProbeCounters(t *testing.T) {
+	healthyServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set(echoServerPortAckHeader, "8080")
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	defer healthyServer.Close()
+	failingServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set(echoServerPortAckHeader, "8080")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	defer failingServer.Close()
+
+	r := &reconciler{
+		config: Config{
+			MaxProbeRetries: 2,
+		},
+	}
+
+	attempts := testutil.ToFloat64(CanaryProbeAttempts)
+	successes := testutil.ToFloat64(CanaryProbeSuccesses)
+	failures := testutil.ToFloat64(CanaryProbeFailures)
+
+	if _, err := r.probeRouteEndpointWithRetries(context.TODO(), routeForServer(healthyServer, "8080"), time.Millisecond); err != nil {
+		t.Fatalf("probeRouteEndpointWithRetries returned an unexpected error: %v", err)
+	}
+	if got := testutil.ToFloat64(CanaryProbeAttempts); got != attempts+1 {
+		t.Errorf("expected the probe attempt counter to be %v after a successful probe, but got %v", attempts+1, got)
+	}
+	if got := testutil.ToFloat64(CanaryProbeSuccesses); got != successes+1 {
+		t.Errorf("expected the probe success counter to be %v after a successful probe, but got %v", successes+1, got)
+	}
+	if got := testutil.ToFloat64(CanaryProbeFailures); got != failures {
+		t.Errorf("expected the probe failure counter to stay at %v after a successful probe, but got %v", failures, got)
+	}
+
+	if _, err := r.probeRouteEndpointWithRetries(context.TODO(), routeForServer(failingServer, "8080"), time.Millisecond); err == nil {
+		t.Fatal("expected probeRouteEndpointWithRetries to return an error for a failing route, but it did not")
+	}
+	if got := testutil.ToFloat64(CanaryProbeAttempts); got != attempts+3 {
+		t.Errorf("expected the probe attempt counter to be %v after two failed attempts, but got %v", attempts+3, got)
+	}
+	if got := testutil.ToFloat64(CanaryProbeSuccesses); got != successes+1 {
+		t.Errorf("expected the probe success counter to stay at %v after a failed probe, but got %v", successes+1, got)
+	}
+	if got := testutil.ToFloat64(CanaryProbeFailures); got != failures+2 {
+		t.Errorf("expected the probe failure counter to be %v after two failed attempts, but got %v", failures+2, got)
+	}
+}
@@ -0,0 +1,116 @@
+package canary
+
+import (
+	"fmt"
+	"net/http"
+
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+// wrongPortCause classifies why a canary probe reached the backend on
+// the wrong port.
+type wrongPortCause string
+
+const (
+	// wrongPortCauseNone means neither probe reached the wrong port.
+	wrongPortCauseNone wrongPortCause = "none"
+	// wrongPortCauseReuseLeak means only a reused keep-alive
+	// connection reached the old backend; a fresh connection reached
+	// the expected port, so the router itself reloaded correctly.
+	wrongPortCauseReuseLeak wrongPortCause = "connection-reuse-leak"
+	// wrongPortCauseRouterWedge means even a fresh connection reached
+	// the wrong port, indicating the router did not pick up the
+	// rotated route target.
+	wrongPortCauseRouterWedge wrongPortCause = "router-wedge"
+)
+
+// classifyWrongPortEcho compares the port echoed over a fresh
+// connection with the port echoed over a reused connection to decide
+// whether a wrong-port echo after a rotation is a connection-reuse
+// leak or a wedged router.
+func classifyWrongPortEcho(expectedPort, freshPort, reusedPort string) wrongPortCause {
+	if freshPort != expectedPort {
+		return wrongPortCauseRouterWedge
+	}
+	if reusedPort != expectedPort {
+		return wrongPortCauseReuseLeak
+	}
+	return wrongPortCauseNone
+}
+
+// probeEchoedPort sends a probe to the given host with the given
+// client and scheme and returns the port that the canary backend
+// echoed.
+func probeEchoedPort(client *http.Client, scheme, host, portHeader string) (string, error) {
+	response, err := client.Get(canaryRouteURL(scheme, host))
+	if err != nil {
+		return "", fmt.Errorf("error sending canary port echo request to %q: %v", host, err)
+	}
+	defer response.Body.Close()
+
+	echoedPort := response.Header.Get(portHeader)
+	if len(echoedPort) == 0 {
+		return "", fmt.Errorf("expected %q header in canary response to have a nonempty value", portHeader)
+	}
+	return echoedPort, nil
+}
+
+// reuseProbeClient returns a persistent keep-alive client so that
+// successive probes reuse pooled connections, mirroring how a
+// long-lived client would behave across a route rotation.
+func (r *reconciler) reuseProbeClient(route *routev1.Route) *http.Client {
+	r.reuseMu.Lock()
+	defer r.reuseMu.Unlock()
+	if r.reuseClient == nil {
+		options := r.probeOptionsForHost(route.Spec.Host)
+		r.reuseClient = &http.Client{
+			Timeout:   options.Timeout,
+			Transport: r.newRouteProbeTransport(route),
+		}
+	}
+	return r.reuseClient
+}
+
+// markRotated records that the route target port was just rotated so
+// that the next wrong-port echo can be classified.
+func (r *reconciler) markRotated() {
+	r.reuseMu.Lock()
+	defer r.reuseMu.Unlock()
+	r.justRotated = true
+}
+
+// consumeJustRotated returns whether the route was rotated since the
+// last call and clears the flag.
+func (r *reconciler) consumeJustRotated() bool {
+	r.reuseMu.Lock()
+	defer r.reuseMu.Unlock()
+	rotated := r.justRotated
+	r.justRotated = false
+	return rotated
+}
+
+// checkPostRotationReuse probes the route once over a forced-fresh
+// connection and once over the reused keep-alive client and classifies
+// a wrong-port echo as either a connection-reuse leak or a router
+// wedge.
+func (r *reconciler) checkPostRotationReuse(route *routev1.Route) (wrongPortCause, error) {
+	options := r.probeOptionsForHost(route.Spec.Host)
+	freshTransport := r.newRouteProbeTransport(route)
+	freshTransport.DisableKeepAlives = true
+	freshClient := &http.Client{
+		Timeout:   options.Timeout,
+		Transport: freshTransport,
+	}
+
+	scheme := routeProbeScheme(route)
+	freshPort, err := probeEchoedPort(freshClient, scheme, route.Spec.Host, r.portEchoHeader())
+	if err != nil {
+		return wrongPortCauseNone, err
+	}
+	reusedPort, err := probeEchoedPort(r.reuseProbeClient(route), scheme, route.Spec.Host, r.portEchoHeader())
+	if err != nil {
+		return wrongPortCauseNone, err
+	}
+
+	return classifyWrongPortEcho(route.Spec.Port.TargetPort.String(), freshPort, reusedPort), nil
+}
@@ -0,0 +1,108 @@
+package canary
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClassifyWrongPortEcho(t *testing.T) {
+	testCases := []struct {
+		description string
+		expected    string
+		fresh       string
+		reused      string
+		expect      wrongPortCause
+	}{
+		{
+			description: "both connections reach the rotated port",
+			expected:    "8888",
+			fresh:       "8888",
+			reused:      "8888",
+			expect:      wrongPortCauseNone,
+		},
+		{
+			description: "only the reused connection reaches the old port",
+			expected:    "8888",
+			fresh:       "8888",
+			reused:      "8080",
+			expect:      wrongPortCauseReuseLeak,
+		},
+		{
+			description: "a fresh connection also reaches the old port",
+			expected:    "8888",
+			fresh:       "8080",
+			reused:      "8080",
+			expect:      wrongPortCauseRouterWedge,
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := classifyWrongPortEcho(tc.expected, tc.fresh, tc.reused); got != tc.expect {
+			t.Errorf("%s: expected cause %q, but got %q", tc.description, tc.expect, got)
+		}
+	}
+}
+
+// TestCheckPostRotationReuseLeak simulates a keep-alive connection
+// pinned to the pre-rotation backend: the reused client always reaches
+// a server echoing the old port, while fresh connections reach the
+// server echoing the rotated port. The wrong-port echo must be
+// classified as a connection-reuse leak rather than a router wedge.
+func TestCheckPostRotationReuseLeak(t *testing.T) {
+	oldBackend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set(echoServerPortAckHeader, "8080")
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	defer oldBackend.Close()
+	newBackend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set(echoServerPortAckHeader, "8888")
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	defer newBackend.Close()
+
+	// Pin the reused client to the old backend by dialing its address
+	// regardless of the requested host, mimicking a pooled connection
+	// that outlived the rotation.
+	oldAddr := strings.TrimPrefix(oldBackend.URL, "https://")
+	pinnedClient := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, oldAddr)
+			},
+		},
+	}
+
+	route := routeForServer(newBackend, "8888")
+	r := &reconciler{
+		reuseClient: pinnedClient,
+	}
+
+	cause, err := r.checkPostRotationReuse(route)
+	if err != nil {
+		t.Fatalf("checkPostRotationReuse returned an error: %v", err)
+	}
+	if cause != wrongPortCauseReuseLeak {
+		t.Errorf("expected cause %q, but got %q", wrongPortCauseReuseLeak, cause)
+	}
+}
+
+func TestConsumeJustRotated(t *testing.T) {
+	r := &reconciler{}
+	if r.consumeJustRotated() {
+		t.Error("expected consumeJustRotated to be false before a rotation")
+	}
+	r.markRotated()
+	if !r.consumeJustRotated() {
+		t.Error("expected consumeJustRotated to be true after a rotation")
+	}
+	if r.consumeJustRotated() {
+		t.Error("expected consumeJustRotated to clear the rotation flag")
+	}
+}
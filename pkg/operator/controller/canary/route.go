@@ -3,6 +3,7 @@ package canary
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
 	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
@@ -14,41 +15,129 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // ensureCanaryRoute ensures the canary route exists
-func (r *reconciler) ensureCanaryRoute(service *corev1.Service) (bool, *routev1.Route, error) {
-	desired, err := desiredCanaryRoute(service)
+func (r *reconciler) ensureCanaryRoute(ctx context.Context, service *corev1.Service) (bool, *routev1.Route, error) {
+	desired, err := desiredCanaryRoute(r.config, service)
 	if err != nil {
 		return false, nil, fmt.Errorf("failed to build canary route: %v", err)
 	}
+	// Place the route in the configured canary namespace.
+	desired.Namespace = r.canaryNamespace()
 
-	haveRoute, current, err := r.currentCanaryRoute()
+	haveRoute, current, err := r.currentCanaryRoute(ctx)
 	if err != nil {
 		return false, nil, err
 	}
 
 	switch {
 	case !haveRoute:
-		if err := r.createCanaryRoute(desired); err != nil {
+		if err := r.createCanaryRoute(ctx, desired); err != nil {
 			return false, nil, err
 		}
-		return r.currentCanaryRoute()
+		return r.currentCanaryRoute(ctx)
 	case haveRoute:
-		if updated, err := r.updateCanaryRoute(current, desired); err != nil {
+		// Preserve the current Spec.Port: the polling loop
+		// deliberately rotates the route's target port, so reconciling
+		// other spec drift must not revert the rotation.
+		if current.Spec.Port != nil {
+			desired.Spec.Port = current.Spec.Port
+		}
+		if updated, err := r.updateCanaryRoute(ctx, current, desired); err != nil {
 			return true, current, err
 		} else if updated {
-			return r.currentCanaryRoute()
+			return r.currentCanaryRoute(ctx)
 		}
 	}
 
 	return true, current, nil
 }
 
+// ensureCanaryRouteHost verifies that the canary route's host falls
+// under the given ingress controller domain and recreates the route if
+// it does not. Recreating the route lets the API server assign a fresh
+// host under the new domain so the router can re-admit the route.
+func (r *reconciler) ensureCanaryRouteHost(ctx context.Context, route *routev1.Route, domain string, service *corev1.Service) (bool, *routev1.Route, error) {
+	if !canaryRouteHostStale(route, domain) {
+		return true, route, nil
+	}
+
+	log.Info("canary route host does not match the current ingresscontroller domain, recreating route", "namespace", route.Namespace, "name", route.Name, "host", route.Spec.Host, "domain", domain)
+	if _, err := r.deleteCanaryRoute(ctx, route); err != nil {
+		return true, route, err
+	}
+
+	return r.ensureCanaryRoute(ctx, service)
+}
+
+// canaryRouteHostStale returns true if the given route has a host that
+// does not belong to the given domain. An empty host or domain means
+// there is nothing to compare, so the route is not considered stale.
+func canaryRouteHostStale(route *routev1.Route, domain string) bool {
+	if route == nil || len(route.Spec.Host) == 0 || len(domain) == 0 {
+		return false
+	}
+	return !strings.HasSuffix(route.Spec.Host, "."+domain)
+}
+
+// ensureSingleCanaryRoute deletes any route in the canary namespace
+// that carries the owning canary label but is not the canonical canary
+// route. Duplicate canary routes (e.g. left behind by a rename
+// migration) compete for the canary host and can cause the router to
+// serve inconsistent results.
+func (r *reconciler) ensureSingleCanaryRoute(ctx context.Context) error {
+	canonical := r.canaryRouteName()
+
+	routeList := &routev1.RouteList{}
+	listOpts := []client.ListOption{
+		client.InNamespace(canonical.Namespace),
+		client.MatchingLabels{manifests.OwningIngressCanaryCheckLabel: canaryControllerName},
+	}
+	if err := r.client.List(ctx, routeList, listOpts...); err != nil {
+		return fmt.Errorf("failed to list canary routes: %v", err)
+	}
+
+	for i := range routeList.Items {
+		route := &routeList.Items[i]
+		if route.Name == canonical.Name {
+			continue
+		}
+		if _, err := r.deleteCanaryRoute(ctx, route); err != nil {
+			return fmt.Errorf("failed to delete duplicate canary route %s/%s: %v", route.Namespace, route.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// listCanaryRoutes returns every route in the canary namespace that
+// carries the owning canary label. The reconciler currently maintains
+// a single canary route, but clusters with per-shard canary routes
+// (one per ingress controller shard) are probed through this list so
+// that each shard records its own reachability.
+func (r *reconciler) listCanaryRoutes(ctx context.Context) ([]routev1.Route, error) {
+	routeList := &routev1.RouteList{}
+	listOpts := []client.ListOption{
+		client.InNamespace(r.canaryNamespace()),
+		client.MatchingLabels{manifests.OwningIngressCanaryCheckLabel: canaryControllerName},
+	}
+	ctx, cancel := apiReadContext(ctx)
+	defer cancel()
+	if err := r.client.List(ctx, routeList, listOpts...); err != nil {
+		return nil, fmt.Errorf("failed to list canary routes: %v", err)
+	}
+	return routeList.Items, nil
+}
+
 // currentCanaryRoute gets the current canary route resource
-func (r *reconciler) currentCanaryRoute() (bool, *routev1.Route, error) {
+func (r *reconciler) currentCanaryRoute(ctx context.Context) (bool, *routev1.Route, error) {
 	route := &routev1.Route{}
-	if err := r.client.Get(context.TODO(), controller.CanaryRouteName(), route); err != nil {
+	ctx, cancel := apiReadContext(ctx)
+	defer cancel()
+	if err := r.client.Get(ctx, r.canaryRouteName(), route); err != nil {
 		if errors.IsNotFound(err) {
 			return false, nil, nil
 		}
@@ -58,8 +147,9 @@ func (r *reconciler) currentCanaryRoute() (bool, *routev1.Route, error) {
 }
 
 // createCanaryRoute creates the given route
-func (r *reconciler) createCanaryRoute(route *routev1.Route) error {
-	if err := r.client.Create(context.TODO(), route); err != nil {
+func (r *reconciler) createCanaryRoute(ctx context.Context, route *routev1.Route) error {
+	r.applyCanaryFinalizer(route)
+	if err := r.retryCreate(func() error { return r.client.Create(ctx, route) }); err != nil {
 		return fmt.Errorf("failed to create canary route %s/%s: %v", route.Namespace, route.Name, err)
 	}
 
@@ -69,7 +159,7 @@ func (r *reconciler) createCanaryRoute(route *routev1.Route) error {
 
 // updateCanaryRoute updates the canary route if an appropriate change
 // has been detected
-func (r *reconciler) updateCanaryRoute(current, desired *routev1.Route) (bool, error) {
+func (r *reconciler) updateCanaryRoute(ctx context.Context, current, desired *routev1.Route) (bool, error) {
 	changed, updated := canaryRouteChanged(current, desired)
 	if !changed {
 		return false, nil
@@ -77,7 +167,7 @@ func (r *reconciler) updateCanaryRoute(current, desired *routev1.Route) (bool, e
 
 	// Diff before updating because the client may mutate the object.
 	diff := cmp.Diff(current, updated, cmpopts.EquateEmpty())
-	if err := r.client.Update(context.TODO(), updated); err != nil {
+	if err := r.client.Update(ctx, updated); err != nil {
 		return false, fmt.Errorf("failed to update canary route %s/%s: %v", updated.Namespace, updated.Name, err)
 	}
 	log.Info("updated canary route", "namespace", updated.Namespace, "name", updated.Name, "diff", diff)
@@ -85,9 +175,15 @@ func (r *reconciler) updateCanaryRoute(current, desired *routev1.Route) (bool, e
 }
 
 // deleteCanaryRoute deletes a given route
-func (r *reconciler) deleteCanaryRoute(route *routev1.Route) (bool, error) {
+func (r *reconciler) deleteCanaryRoute(ctx context.Context, route *routev1.Route) (bool, error) {
+	if err := r.removeCanaryFinalizer(route); err != nil {
+		return false, err
+	}
 
-	if err := r.client.Delete(context.TODO(), route); err != nil {
+	if err := r.client.Delete(ctx, route); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
 		return false, fmt.Errorf("failed to delete canary route %s/%s: %v", route.Namespace, route.Name, err)
 	}
 
@@ -124,7 +220,7 @@ func canaryRouteChanged(current, expected *routev1.Route) (bool, *routev1.Route)
 
 // desiredCanaryRoute returns the desired canary route read in
 // from manifests
-func desiredCanaryRoute(service *corev1.Service) (*routev1.Route, error) {
+func desiredCanaryRoute(config Config, service *corev1.Service) (*routev1.Route, error) {
 	route := manifests.CanaryRoute()
 
 	name := controller.CanaryRouteName()
@@ -153,11 +249,53 @@ func desiredCanaryRoute(service *corev1.Service) (*routev1.Route, error) {
 	}
 	route.Spec.Port.TargetPort = service.Spec.Ports[0].TargetPort
 
+	// Apply the configured wildcard policy and subdomain, if any, so
+	// that the canary can validate wildcard admission. A subdomain
+	// route leaves Spec.Host empty for the router to assign via status.
+	if len(config.RouteWildcardPolicy) != 0 {
+		route.Spec.WildcardPolicy = config.RouteWildcardPolicy
+	}
+	if len(config.RouteSubdomain) != 0 {
+		route.Spec.Subdomain = config.RouteSubdomain
+	}
+
 	route.SetOwnerReferences(service.OwnerReferences)
 
 	return route, nil
 }
 
+// admittedRouteHost returns the host that a router admitted the given
+// route under, or the empty string if the route has not been admitted.
+// Routes that specify Spec.Subdomain rather than Spec.Host only carry
+// their host in status, so probes must read it from there.
+func admittedRouteHost(route *routev1.Route) string {
+	for _, routeIngress := range route.Status.Ingress {
+		for _, cond := range routeIngress.Conditions {
+			if cond.Type == routev1.RouteAdmitted && cond.Status == corev1.ConditionTrue {
+				return routeIngress.Host
+			}
+		}
+	}
+
+	return ""
+}
+
+// checkRouteAdmittedByAny returns true if a given route has been
+// admitted by any router. Per-shard canary routes are admitted by
+// their shard's router rather than the default ingress controller, so
+// checkRouteAdmitted is too strict for them.
+func checkRouteAdmittedByAny(route *routev1.Route) bool {
+	for _, routeIngress := range route.Status.Ingress {
+		for _, cond := range routeIngress.Conditions {
+			if cond.Type == routev1.RouteAdmitted && cond.Status == corev1.ConditionTrue {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 // checkRouteAdmitted returns true if a given route has been admitted
 // by the default Ingress Controller.
 func checkRouteAdmitted(route *routev1.Route) bool {
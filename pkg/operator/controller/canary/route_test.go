@@ -1,6 +1,7 @@
 package canary
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -8,10 +9,15 @@ import (
 	routev1 "github.com/openshift/api/route/v1"
 
 	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestDesiredCanaryRoute(t *testing.T) {
@@ -19,7 +25,7 @@ func TestDesiredCanaryRoute(t *testing.T) {
 		Name: "test",
 	}
 	service := desiredCanaryService(daemonsetRef)
-	route, err := desiredCanaryRoute(service)
+	route, err := desiredCanaryRoute(Config{}, service)
 
 	if err != nil {
 		t.Fatalf("desiredCanaryService returned an error: %v", err)
@@ -110,6 +116,16 @@ func TestCanaryRouteChanged(t *testing.T) {
 			},
 			expect: true,
 		},
+		{
+			// A route that lost its port entirely (manual edit or a
+			// manifest regression) must be repaired from the desired
+			// route.
+			description: "if route spec.Port is removed",
+			mutate: func(route *routev1.Route) {
+				route.Spec.Port = nil
+			},
+			expect: true,
+		},
 		{
 			description: "if route spec.TLS changes",
 			mutate: func(route *routev1.Route) {
@@ -119,6 +135,16 @@ func TestCanaryRouteChanged(t *testing.T) {
 			},
 			expect: true,
 		},
+		{
+			// Spec.Host is populated by route admission after creation,
+			// so a live route with a host must not forever differ from
+			// the desired route, whose host is empty.
+			description: "if route spec.Host is populated by admission",
+			mutate: func(route *routev1.Route) {
+				route.Spec.Host = "canary-openshift-ingress-canary.apps.example.com"
+			},
+			expect: false,
+		},
 	}
 
 	daemonsetRef := metav1.OwnerReference{
@@ -127,7 +153,7 @@ func TestCanaryRouteChanged(t *testing.T) {
 	service := desiredCanaryService(daemonsetRef)
 
 	for _, tc := range testCases {
-		original, err := desiredCanaryRoute(service)
+		original, err := desiredCanaryRoute(Config{}, service)
 		if err != nil {
 			t.Fatalf("desiredCanaryService returned an error: %v", err)
 		}
@@ -142,3 +168,504 @@ func TestCanaryRouteChanged(t *testing.T) {
 		}
 	}
 }
+
+func TestEnsureSingleCanaryRoute(t *testing.T) {
+	canonicalName := controller.CanaryRouteName()
+	canonical := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: canonicalName.Namespace,
+			Name:      canonicalName.Name,
+			Labels: map[string]string{
+				manifests.OwningIngressCanaryCheckLabel: canaryControllerName,
+			},
+		},
+	}
+	duplicate := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: canonicalName.Namespace,
+			Name:      "canary-old",
+			Labels: map[string]string{
+				manifests.OwningIngressCanaryCheckLabel: canaryControllerName,
+			},
+		},
+	}
+	unrelated := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: canonicalName.Namespace,
+			Name:      "not-canary",
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(operatorclient.GetScheme()).WithObjects(canonical, duplicate, unrelated).Build()
+	r := &reconciler{
+		client: fakeClient,
+	}
+
+	if err := r.ensureSingleCanaryRoute(context.TODO()); err != nil {
+		t.Fatalf("ensureSingleCanaryRoute returned an error: %v", err)
+	}
+
+	routeList := &routev1.RouteList{}
+	if err := fakeClient.List(context.TODO(), routeList); err != nil {
+		t.Fatalf("failed to list routes: %v", err)
+	}
+
+	remaining := map[string]bool{}
+	for _, route := range routeList.Items {
+		remaining[route.Name] = true
+	}
+
+	if remaining[duplicate.Name] {
+		t.Errorf("expected duplicate canary route %q to be deleted", duplicate.Name)
+	}
+	if !remaining[canonical.Name] {
+		t.Errorf("expected canonical canary route %q to remain", canonical.Name)
+	}
+	if !remaining[unrelated.Name] {
+		t.Errorf("expected unlabeled route %q to remain", unrelated.Name)
+	}
+}
+
+func TestEnsureCanaryRouteReconcilesDrift(t *testing.T) {
+	daemonsetRef := metav1.OwnerReference{
+		Name: "test",
+	}
+	service := desiredCanaryService(daemonsetRef)
+	if len(service.Spec.Ports) < 2 {
+		t.Fatalf("expected the canary service to have at least 2 ports, but got %d", len(service.Spec.Ports))
+	}
+	rotatedPort := service.Spec.Ports[1].TargetPort
+
+	desired, err := desiredCanaryRoute(Config{}, service)
+	if err != nil {
+		t.Fatalf("desiredCanaryRoute returned an error: %v", err)
+	}
+
+	// Simulate a user edit of Spec.To alongside a deliberately rotated
+	// Spec.Port.
+	mutated := desired.DeepCopy()
+	mutated.Spec.To.Name = "not-the-canary-service"
+	mutated.Spec.Port = &routev1.RoutePort{
+		TargetPort: rotatedPort,
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(operatorclient.GetScheme()).WithObjects(mutated).Build()
+	r := &reconciler{
+		client: fakeClient,
+	}
+
+	haveRoute, route, err := r.ensureCanaryRoute(context.TODO(), service)
+	if err != nil {
+		t.Fatalf("ensureCanaryRoute returned an error: %v", err)
+	}
+	if !haveRoute {
+		t.Fatal("expected ensureCanaryRoute to return a route")
+	}
+
+	if route.Spec.To.Name != desired.Spec.To.Name {
+		t.Errorf("expected route.Spec.To.Name to be reconciled back to %q, but got %q", desired.Spec.To.Name, route.Spec.To.Name)
+	}
+	if !cmp.Equal(route.Spec.Port.TargetPort, rotatedPort) {
+		t.Errorf("expected rotated route target port %v to be left alone, but got %v", rotatedPort, route.Spec.Port.TargetPort)
+	}
+}
+
+func TestCanaryRouteHostStale(t *testing.T) {
+	testCases := []struct {
+		description string
+		host        string
+		domain      string
+		expect      bool
+	}{
+		{
+			description: "host under the current domain",
+			host:        "canary-openshift-ingress-canary.apps.example.com",
+			domain:      "apps.example.com",
+			expect:      false,
+		},
+		{
+			description: "host under a stale domain",
+			host:        "canary-openshift-ingress-canary.apps.old.example.com",
+			domain:      "apps.new.example.com",
+			expect:      true,
+		},
+		{
+			description: "empty host",
+			host:        "",
+			domain:      "apps.example.com",
+			expect:      false,
+		},
+		{
+			description: "empty domain",
+			host:        "canary-openshift-ingress-canary.apps.example.com",
+			domain:      "",
+			expect:      false,
+		},
+	}
+
+	for _, tc := range testCases {
+		route := &routev1.Route{
+			Spec: routev1.RouteSpec{
+				Host: tc.host,
+			},
+		}
+		if got := canaryRouteHostStale(route, tc.domain); got != tc.expect {
+			t.Errorf("%s: expected canaryRouteHostStale to be %t, but got %t", tc.description, tc.expect, got)
+		}
+	}
+}
+
+func TestEnsureCanaryRouteHost(t *testing.T) {
+	daemonsetRef := metav1.OwnerReference{
+		Name: "test",
+	}
+	service := desiredCanaryService(daemonsetRef)
+
+	routeName := controller.CanaryRouteName()
+	stale := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: routeName.Namespace,
+			Name:      routeName.Name,
+			Labels: map[string]string{
+				manifests.OwningIngressCanaryCheckLabel: canaryControllerName,
+			},
+		},
+		Spec: routev1.RouteSpec{
+			Host: "canary-openshift-ingress-canary.apps.old.example.com",
+			Port: &routev1.RoutePort{
+				TargetPort: intstr.FromString("8080"),
+			},
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(operatorclient.GetScheme()).WithObjects(stale).Build()
+	r := &reconciler{
+		client: fakeClient,
+	}
+
+	haveRoute, route, err := r.ensureCanaryRouteHost(context.TODO(), stale, "apps.new.example.com", service)
+	if err != nil {
+		t.Fatalf("ensureCanaryRouteHost returned an error: %v", err)
+	}
+	if !haveRoute {
+		t.Fatal("expected ensureCanaryRouteHost to return a route")
+	}
+	if canaryRouteHostStale(route, "apps.new.example.com") {
+		t.Errorf("expected repaired canary route host to not be stale, but got host %q", route.Spec.Host)
+	}
+
+	// A route whose host already matches the domain is left alone.
+	haveRoute, unchanged, err := r.ensureCanaryRouteHost(context.TODO(), route, "apps.new.example.com", service)
+	if err != nil {
+		t.Fatalf("ensureCanaryRouteHost returned an error: %v", err)
+	}
+	if !haveRoute {
+		t.Fatal("expected ensureCanaryRouteHost to return a route")
+	}
+	if unchanged.ResourceVersion != route.ResourceVersion {
+		t.Errorf("expected canary route to be unchanged, but the resource version changed from %q to %q", route.ResourceVersion, unchanged.ResourceVersion)
+	}
+}
+
+func TestListCanaryRoutes(t *testing.T) {
+	canonicalName := controller.CanaryRouteName()
+	canonical := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: canonicalName.Namespace,
+			Name:      canonicalName.Name,
+			Labels: map[string]string{
+				manifests.OwningIngressCanaryCheckLabel: canaryControllerName,
+			},
+		},
+	}
+	sharded := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: canonicalName.Namespace,
+			Name:      "canary-shard-a",
+			Labels: map[string]string{
+				manifests.OwningIngressCanaryCheckLabel: canaryControllerName,
+			},
+		},
+	}
+	unrelated := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: canonicalName.Namespace,
+			Name:      "not-canary",
+		},
+	}
+
+	r := &reconciler{
+		client: fake.NewClientBuilder().WithScheme(operatorclient.GetScheme()).WithObjects(canonical, sharded, unrelated).Build(),
+	}
+
+	routes, err := r.listCanaryRoutes(context.TODO())
+	if err != nil {
+		t.Fatalf("listCanaryRoutes returned an error: %v", err)
+	}
+
+	listed := map[string]bool{}
+	for _, route := range routes {
+		listed[route.Name] = true
+	}
+
+	if len(routes) != 2 {
+		t.Errorf("expected listCanaryRoutes to return 2 routes, but got %d", len(routes))
+	}
+	if !listed[canonical.Name] {
+		t.Errorf("expected listCanaryRoutes to include route %q", canonical.Name)
+	}
+	if !listed[sharded.Name] {
+		t.Errorf("expected listCanaryRoutes to include route %q", sharded.Name)
+	}
+	if listed[unrelated.Name] {
+		t.Errorf("expected listCanaryRoutes to exclude unlabeled route %q", unrelated.Name)
+	}
+}
+
+func TestCheckRouteAdmittedByAny(t *testing.T) {
+	testCases := []struct {
+		description string
+		ingress     []routev1.RouteIngress
+		expect      bool
+	}{
+		{
+			description: "if the route has no status",
+			ingress:     nil,
+			expect:      false,
+		},
+		{
+			description: "if the route is admitted by the default router",
+			ingress: []routev1.RouteIngress{
+				{
+					RouterName: manifests.DefaultIngressControllerName,
+					Conditions: []routev1.RouteIngressCondition{
+						{
+							Type:   routev1.RouteAdmitted,
+							Status: corev1.ConditionTrue,
+						},
+					},
+				},
+			},
+			expect: true,
+		},
+		{
+			description: "if the route is admitted by a shard router only",
+			ingress: []routev1.RouteIngress{
+				{
+					RouterName: "shard-a",
+					Conditions: []routev1.RouteIngressCondition{
+						{
+							Type:   routev1.RouteAdmitted,
+							Status: corev1.ConditionTrue,
+						},
+					},
+				},
+			},
+			expect: true,
+		},
+		{
+			description: "if admission was rejected",
+			ingress: []routev1.RouteIngress{
+				{
+					RouterName: "shard-a",
+					Conditions: []routev1.RouteIngressCondition{
+						{
+							Type:   routev1.RouteAdmitted,
+							Status: corev1.ConditionFalse,
+						},
+					},
+				},
+			},
+			expect: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		route := &routev1.Route{
+			Status: routev1.RouteStatus{
+				Ingress: tc.ingress,
+			},
+		}
+		if admitted := checkRouteAdmittedByAny(route); admitted != tc.expect {
+			t.Errorf("%s: expected checkRouteAdmittedByAny to be %t, but got %t", tc.description, tc.expect, admitted)
+		}
+	}
+}
+
+func TestCheckRouteAdmitted(t *testing.T) {
+	testCases := []struct {
+		description string
+		ingress     []routev1.RouteIngress
+		expect      bool
+	}{
+		{
+			description: "if the route has no status",
+			ingress:     nil,
+			expect:      false,
+		},
+		{
+			description: "if the route is admitted by the default router",
+			ingress: []routev1.RouteIngress{
+				{
+					RouterName: manifests.DefaultIngressControllerName,
+					Conditions: []routev1.RouteIngressCondition{
+						{
+							Type:   routev1.RouteAdmitted,
+							Status: corev1.ConditionTrue,
+						},
+					},
+				},
+			},
+			expect: true,
+		},
+		{
+			description: "if the default router rejected admission",
+			ingress: []routev1.RouteIngress{
+				{
+					RouterName: manifests.DefaultIngressControllerName,
+					Conditions: []routev1.RouteIngressCondition{
+						{
+							Type:   routev1.RouteAdmitted,
+							Status: corev1.ConditionFalse,
+						},
+					},
+				},
+			},
+			expect: false,
+		},
+		{
+			description: "if only a non-default router admitted the route",
+			ingress: []routev1.RouteIngress{
+				{
+					RouterName: "shard-a",
+					Conditions: []routev1.RouteIngressCondition{
+						{
+							Type:   routev1.RouteAdmitted,
+							Status: corev1.ConditionTrue,
+						},
+					},
+				},
+			},
+			expect: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		route := &routev1.Route{
+			Status: routev1.RouteStatus{
+				Ingress: tc.ingress,
+			},
+		}
+		if admitted := checkRouteAdmitted(route); admitted != tc.expect {
+			t.Errorf("%s: expected checkRouteAdmitted to be %t, but got %t", tc.description, tc.expect, admitted)
+		}
+	}
+}
+
+// TestDesiredCanaryRouteWildcardPolicy verifies that the configured
+// wildcard policy and subdomain are applied to the desired canary
+// route spec, and that the default configuration leaves them unset.
+func TestDesiredCanaryRouteWildcardPolicy(t *testing.T) {
+	daemonsetRef := metav1.OwnerReference{}
+	service := desiredCanaryService(daemonsetRef)
+
+	testCases := []struct {
+		description       string
+		config            Config
+		expectedPolicy    routev1.WildcardPolicyType
+		expectedSubdomain string
+	}{
+		{
+			description:    "default config leaves the manifest's policy",
+			config:         Config{},
+			expectedPolicy: routev1.WildcardPolicyNone,
+		},
+		{
+			description: "wildcard policy is applied",
+			config: Config{
+				RouteWildcardPolicy: routev1.WildcardPolicySubdomain,
+			},
+			expectedPolicy: routev1.WildcardPolicySubdomain,
+		},
+		{
+			description: "subdomain is applied",
+			config: Config{
+				RouteWildcardPolicy: routev1.WildcardPolicySubdomain,
+				RouteSubdomain:      "canary",
+			},
+			expectedPolicy:    routev1.WildcardPolicySubdomain,
+			expectedSubdomain: "canary",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			route, err := desiredCanaryRoute(tc.config, service)
+			if err != nil {
+				t.Fatalf("desiredCanaryRoute returned an error: %v", err)
+			}
+			if route.Spec.WildcardPolicy != tc.expectedPolicy {
+				t.Errorf("expected wildcard policy %q, but got %q", tc.expectedPolicy, route.Spec.WildcardPolicy)
+			}
+			if route.Spec.Subdomain != tc.expectedSubdomain {
+				t.Errorf("expected subdomain %q, but got %q", tc.expectedSubdomain, route.Spec.Subdomain)
+			}
+		})
+	}
+}
+
+// TestAdmittedRouteHost verifies that the admitted host is read from
+// route status and that unadmitted routes yield no host.
+func TestAdmittedRouteHost(t *testing.T) {
+	testCases := []struct {
+		description  string
+		status       routev1.RouteStatus
+		expectedHost string
+	}{
+		{
+			description: "no status",
+		},
+		{
+			description: "unadmitted ingress",
+			status: routev1.RouteStatus{
+				Ingress: []routev1.RouteIngress{
+					{
+						Host: "canary.apps.example.com",
+						Conditions: []routev1.RouteIngressCondition{
+							{
+								Type:   routev1.RouteAdmitted,
+								Status: corev1.ConditionFalse,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			description: "admitted ingress",
+			status: routev1.RouteStatus{
+				Ingress: []routev1.RouteIngress{
+					{
+						Host: "canary.apps.example.com",
+						Conditions: []routev1.RouteIngressCondition{
+							{
+								Type:   routev1.RouteAdmitted,
+								Status: corev1.ConditionTrue,
+							},
+						},
+					},
+				},
+			},
+			expectedHost: "canary.apps.example.com",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			route := &routev1.Route{Status: tc.status}
+			if host := admittedRouteHost(route); host != tc.expectedHost {
+				t.Errorf("expected admitted host %q, but got %q", tc.expectedHost, host)
+			}
+		})
+	}
+}
@@ -7,31 +7,47 @@ import (
 	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
 	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // ensureCanaryService ensures the ingress canary service exists
-func (r *reconciler) ensureCanaryService(daemonsetRef metav1.OwnerReference) (bool, *corev1.Service, error) {
+func (r *reconciler) ensureCanaryService(ctx context.Context, daemonsetRef metav1.OwnerReference) (bool, *corev1.Service, error) {
 	desired := desiredCanaryService(daemonsetRef)
-	haveService, current, err := r.currentCanaryService()
+	// Place the service in the configured canary namespace.
+	desired.Namespace = r.canaryNamespace()
+	// Expose the canonical configured port list for port rotation.
+	desired.Spec.Ports = desiredCanaryPorts(r.config)
+	haveService, current, err := r.currentCanaryService(ctx)
 	if err != nil {
 		return false, nil, err
 	}
 	if haveService {
+		if updated, err := r.updateCanaryService(ctx, current, desired); err != nil {
+			return true, current, err
+		} else if updated {
+			return r.currentCanaryService(ctx)
+		}
 		return true, current, nil
 	}
-	if err := r.createCanaryService(desired); err != nil {
+	if err := r.createCanaryService(ctx, desired); err != nil {
 		return false, nil, err
 	}
 	return true, desired, nil
 }
 
 // currentCanaryService gets the current ingress canary service resource
-func (r *reconciler) currentCanaryService() (bool, *corev1.Service, error) {
+func (r *reconciler) currentCanaryService(ctx context.Context) (bool, *corev1.Service, error) {
 	current := &corev1.Service{}
-	err := r.client.Get(context.TODO(), controller.CanaryServiceName(), current)
+	ctx, cancel := apiReadContext(ctx)
+	defer cancel()
+	err := r.client.Get(ctx, r.canaryServiceName(), current)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			return false, nil, nil
@@ -42,8 +58,9 @@ func (r *reconciler) currentCanaryService() (bool, *corev1.Service, error) {
 }
 
 // createCanaryService creates the given service resource
-func (r *reconciler) createCanaryService(service *corev1.Service) error {
-	if err := r.client.Create(context.TODO(), service); err != nil {
+func (r *reconciler) createCanaryService(ctx context.Context, service *corev1.Service) error {
+	r.applyCanaryFinalizer(service)
+	if err := r.retryCreate(func() error { return r.client.Create(ctx, service) }); err != nil {
 		return fmt.Errorf("failed to create canary service %s/%s: %v", service.Namespace, service.Name, err)
 	}
 
@@ -51,6 +68,128 @@ func (r *reconciler) createCanaryService(service *corev1.Service) error {
 	return nil
 }
 
+// deleteCanaryService deletes the given service resource, tolerating
+// an already-deleted service
+func (r *reconciler) deleteCanaryService(ctx context.Context, service *corev1.Service) error {
+	if err := r.removeCanaryFinalizer(service); err != nil {
+		return err
+	}
+
+	if err := r.client.Delete(ctx, service); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete canary service %s/%s: %v", service.Namespace, service.Name, err)
+	}
+
+	log.Info("deleted canary service", "namespace", service.Namespace, "name", service.Name)
+	return nil
+}
+
+// updateCanaryService updates the canary service if an appropriate
+// change has been detected
+func (r *reconciler) updateCanaryService(ctx context.Context, current, desired *corev1.Service) (bool, error) {
+	changed, updated := canaryServiceChanged(current, desired)
+	if !changed {
+		return false, nil
+	}
+
+	// Diff before updating because the client may mutate the object.
+	diff := cmp.Diff(current, updated, cmpopts.EquateEmpty())
+	if err := r.client.Update(ctx, updated); err != nil {
+		return false, fmt.Errorf("failed to update canary service %s/%s: %v", updated.Namespace, updated.Name, err)
+	}
+	log.Info("updated canary service", "namespace", updated.Namespace, "name", updated.Name, "diff", diff)
+	return true, nil
+}
+
+// canaryServiceChanged returns true if current and expected differ by
+// Spec.Selector, Spec.Ports, or owner references. Cluster-assigned
+// fields such as ClusterIP and NodePort are ignored so that
+// reconciliation does not fight the service controller.
+func canaryServiceChanged(current, expected *corev1.Service) (bool, *corev1.Service) {
+	changed := false
+	updated := current.DeepCopy()
+
+	if !cmp.Equal(current.Spec.Selector, expected.Spec.Selector, cmpopts.EquateEmpty()) {
+		updated.Spec.Selector = expected.Spec.Selector
+		changed = true
+	}
+
+	portCmpOpts := []cmp.Option{
+		cmpopts.EquateEmpty(),
+		cmpopts.IgnoreFields(corev1.ServicePort{}, "NodePort"),
+	}
+	if !cmp.Equal(current.Spec.Ports, expected.Spec.Ports, portCmpOpts...) {
+		updated.Spec.Ports = expected.Spec.Ports
+		changed = true
+	}
+
+	if !cmp.Equal(current.OwnerReferences, expected.OwnerReferences, cmpopts.EquateEmpty()) {
+		updated.SetOwnerReferences(expected.OwnerReferences)
+		changed = true
+	}
+
+	if !changed {
+		return false, nil
+	}
+	return true, updated
+}
+
+// desiredCanaryPorts returns the canonical canary port list derived
+// from the given configuration. The service ports, the canary
+// container's ports, and the rotation logic are all built from this
+// single list so that they cannot drift apart.
+func desiredCanaryPorts(config Config) []corev1.ServicePort {
+	return desiredCanaryServicePorts(manifests.CanaryService().Spec.Ports, config.ServicePortCount)
+}
+
+// canaryPortsAligned returns an error if any of the given service's
+// target ports has no matching port on the canary container, meaning
+// probes to that port would have no listener behind them.
+func canaryPortsAligned(daemonset *appsv1.DaemonSet, service *corev1.Service) error {
+	if len(daemonset.Spec.Template.Spec.Containers) == 0 {
+		return fmt.Errorf("canary daemonset has no containers")
+	}
+	containerPorts := map[int]struct{}{}
+	for _, port := range daemonset.Spec.Template.Spec.Containers[0].Ports {
+		containerPorts[int(port.ContainerPort)] = struct{}{}
+	}
+	for _, port := range service.Spec.Ports {
+		if _, ok := containerPorts[port.TargetPort.IntValue()]; !ok {
+			return fmt.Errorf("canary service target port %s has no matching canary container port", port.TargetPort.String())
+		}
+	}
+	return nil
+}
+
+// desiredCanaryServicePorts returns the canary service port list for
+// the given port count. A count of zero keeps the manifest's ports
+// unchanged, a smaller count truncates the list, and a larger count
+// extends it with sequential ports following the manifest's last port
+// so that the generated list is deterministic.
+func desiredCanaryServicePorts(manifestPorts []corev1.ServicePort, count int) []corev1.ServicePort {
+	if count <= 0 || count == len(manifestPorts) || len(manifestPorts) == 0 {
+		return manifestPorts
+	}
+	if count < len(manifestPorts) {
+		return manifestPorts[:count]
+	}
+
+	ports := append([]corev1.ServicePort{}, manifestPorts...)
+	next := manifestPorts[len(manifestPorts)-1].Port
+	for i := len(manifestPorts); i < count; i++ {
+		next++
+		ports = append(ports, corev1.ServicePort{
+			Name:       fmt.Sprintf("%d-tcp", next),
+			Port:       next,
+			Protocol:   corev1.ProtocolTCP,
+			TargetPort: intstr.FromInt(int(next)),
+		})
+	}
+	return ports
+}
+
 // desiredCanaryService returns the desired canary service read in from manifests
 func desiredCanaryService(daemonsetRef metav1.OwnerReference) *corev1.Service {
 	s := manifests.CanaryService()
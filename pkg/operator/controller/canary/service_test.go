@@ -1,6 +1,7 @@
 package canary
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -8,8 +9,11 @@ import (
 	"github.com/openshift/cluster-ingress-operator/pkg/manifests"
 	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func TestDesiredCanaryService(t *testing.T) {
@@ -51,3 +55,190 @@ func TestDesiredCanaryService(t *testing.T) {
 		t.Errorf("expected service owner references %#v, but got %#v", expectedOwnerRefs, service.OwnerReferences)
 	}
 }
+
+func TestCanaryServiceChanged(t *testing.T) {
+	testCases := []struct {
+		description string
+		mutate      func(*corev1.Service)
+		expect      bool
+	}{
+		{
+			description: "if nothing changes",
+			mutate:      func(_ *corev1.Service) {},
+			expect:      false,
+		},
+		{
+			description: "if the service selector changes",
+			mutate: func(service *corev1.Service) {
+				service.Spec.Selector = map[string]string{"test": "test"}
+			},
+			expect: true,
+		},
+		{
+			description: "if a service port changes",
+			mutate: func(service *corev1.Service) {
+				service.Spec.Ports[0].Port = 12345
+			},
+			expect: true,
+		},
+		{
+			description: "if only a cluster-assigned NodePort changes",
+			mutate: func(service *corev1.Service) {
+				service.Spec.Ports[0].NodePort = 30123
+			},
+			expect: false,
+		},
+		{
+			description: "if only the cluster-assigned ClusterIP changes",
+			mutate: func(service *corev1.Service) {
+				service.Spec.ClusterIP = "172.30.0.1"
+			},
+			expect: false,
+		},
+		{
+			description: "if the owner references change",
+			mutate: func(service *corev1.Service) {
+				service.SetOwnerReferences([]metav1.OwnerReference{{Name: "other"}})
+			},
+			expect: true,
+		},
+	}
+
+	daemonsetRef := metav1.OwnerReference{
+		Name: "test",
+	}
+
+	for _, tc := range testCases {
+		original := desiredCanaryService(daemonsetRef)
+		mutated := original.DeepCopy()
+		tc.mutate(mutated)
+		if changed, updated := canaryServiceChanged(mutated, original); changed != tc.expect {
+			t.Errorf("%s, expect canaryServiceChanged to be %t, got %t", tc.description, tc.expect, changed)
+		} else if changed {
+			if changedAgain, _ := canaryServiceChanged(updated, original); changedAgain {
+				t.Errorf("%s, canaryServiceChanged does not behave as a fixed point function", tc.description)
+			}
+		}
+	}
+}
+
+func TestDesiredCanaryServicePorts(t *testing.T) {
+	manifestPorts := manifests.CanaryService().Spec.Ports
+	if len(manifestPorts) != 2 {
+		t.Fatalf("expected the canary service manifest to have 2 ports, but got %d", len(manifestPorts))
+	}
+
+	testCases := []struct {
+		description   string
+		count         int
+		expectedPorts []int32
+	}{
+		{
+			description:   "if the count is unset",
+			count:         0,
+			expectedPorts: []int32{8080, 8888},
+		},
+		{
+			description:   "if the count matches the manifest",
+			count:         2,
+			expectedPorts: []int32{8080, 8888},
+		},
+		{
+			description:   "if the count truncates the manifest",
+			count:         1,
+			expectedPorts: []int32{8080},
+		},
+		{
+			description:   "if the count extends the manifest",
+			count:         4,
+			expectedPorts: []int32{8080, 8888, 8889, 8890},
+		},
+	}
+
+	for _, tc := range testCases {
+		ports := desiredCanaryServicePorts(manifestPorts, tc.count)
+		if len(ports) != len(tc.expectedPorts) {
+			t.Errorf("%s: expected %d ports, but got %d", tc.description, len(tc.expectedPorts), len(ports))
+			continue
+		}
+		for i, port := range ports {
+			if port.Port != tc.expectedPorts[i] {
+				t.Errorf("%s: expected port %d at index %d, but got %d", tc.description, tc.expectedPorts[i], i, port.Port)
+			}
+			if port.TargetPort.IntValue() != int(tc.expectedPorts[i]) {
+				t.Errorf("%s: expected target port %d at index %d, but got %s", tc.description, tc.expectedPorts[i], i, port.TargetPort.String())
+			}
+			expectedName := fmt.Sprintf("%d-tcp", tc.expectedPorts[i])
+			if port.Name != expectedName {
+				t.Errorf("%s: expected port name %q at index %d, but got %q", tc.description, expectedName, i, port.Name)
+			}
+		}
+	}
+}
+
+func TestSetCanaryContainerPorts(t *testing.T) {
+	daemonset := desiredCanaryDaemonSet("", corev1.ResourceRequirements{})
+	ports := desiredCanaryServicePorts(manifests.CanaryService().Spec.Ports, 3)
+
+	setCanaryContainerPorts(daemonset, ports)
+
+	container := daemonset.Spec.Template.Spec.Containers[0]
+	if len(container.Ports) != 3 {
+		t.Fatalf("expected the canary container to have 3 ports, but got %d", len(container.Ports))
+	}
+	expectedPorts := []int32{8080, 8888, 8889}
+	for i, port := range container.Ports {
+		if port.ContainerPort != expectedPorts[i] {
+			t.Errorf("expected container port %d at index %d, but got %d", expectedPorts[i], i, port.ContainerPort)
+		}
+	}
+
+	portsEnv := ""
+	for _, env := range container.Env {
+		if env.Name == CanaryHealthcheckPortsEnvName {
+			portsEnv = env.Value
+		}
+	}
+	if expected := "8080,8888,8889"; portsEnv != expected {
+		t.Errorf("expected the %s environment variable to be %q, but got %q", CanaryHealthcheckPortsEnvName, expected, portsEnv)
+	}
+}
+
+// TestCanaryPortsAligned verifies that the canary daemonset's container
+// ports and the canary service's target ports are validated against
+// each other, and that the port lists derived from the same
+// configuration always align.
+func TestCanaryPortsAligned(t *testing.T) {
+	newAlignedObjects := func(portCount int) (*appsv1.DaemonSet, *corev1.Service) {
+		config := Config{ServicePortCount: portCount}
+		daemonset := desiredCanaryDaemonSet("", corev1.ResourceRequirements{})
+		if portCount > 0 {
+			setCanaryContainerPorts(daemonset, desiredCanaryPorts(config))
+		}
+		service := desiredCanaryService(metav1.OwnerReference{})
+		service.Spec.Ports = desiredCanaryPorts(config)
+		return daemonset, service
+	}
+
+	for _, portCount := range []int{0, 1, 2, 5} {
+		daemonset, service := newAlignedObjects(portCount)
+		if err := canaryPortsAligned(daemonset, service); err != nil {
+			t.Errorf("expected ports derived from a port count of %d to align, but got error: %v", portCount, err)
+		}
+	}
+
+	daemonset, service := newAlignedObjects(2)
+	service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{
+		Name:       "9999-tcp",
+		Port:       9999,
+		TargetPort: intstr.FromInt(9999),
+	})
+	if err := canaryPortsAligned(daemonset, service); err == nil {
+		t.Error("expected a service target port with no matching container port to be rejected, but it was not")
+	}
+
+	daemonset.Spec.Template.Spec.Containers = nil
+	if err := canaryPortsAligned(daemonset, service); err == nil {
+		t.Error("expected a daemonset with no containers to be rejected, but it was not")
+	}
+}
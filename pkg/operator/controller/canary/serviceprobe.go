@@ -0,0 +1,94 @@
+package canary
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// canaryServiceURL builds the in-cluster probe URL for the given canary
+// service from its ClusterIP and first port. Probing the service
+// bypasses the router, so comparing its result with the route probe
+// localizes failures to the router versus the canary pods.
+func canaryServiceURL(service *corev1.Service) (string, error) {
+	clusterIP := service.Spec.ClusterIP
+	if len(clusterIP) == 0 || clusterIP == corev1.ClusterIPNone {
+		return "", fmt.Errorf("canary service %s/%s has no ClusterIP", service.Namespace, service.Name)
+	}
+	if len(service.Spec.Ports) == 0 {
+		return "", fmt.Errorf("canary service %s/%s has no ports", service.Namespace, service.Name)
+	}
+
+	return "http://" + net.JoinHostPort(clusterIP, strconv.Itoa(int(service.Spec.Ports[0].Port))), nil
+}
+
+// probeCanaryService probes the canary service's ClusterIP directly and
+// records the result in the service reachability gauge. The service is
+// probed over plain http since the canary pods terminate no TLS; the
+// router's TLS path is covered by the route probe.
+func (r *reconciler) probeCanaryService(ctx context.Context) {
+	haveService, service, err := r.currentCanaryService(ctx)
+	if err != nil {
+		log.Error(err, "failed to get canary service for canary service check")
+		return
+	} else if !haveService {
+		log.Info("canary service does not exist, skipping canary service check")
+		return
+	}
+
+	url, err := canaryServiceURL(service)
+	if err != nil {
+		log.Error(err, "error building canary service URL")
+		return
+	}
+
+	err = r.probeServiceURL(ctx, url)
+	SetCanaryServiceReachableMetric(err == nil)
+	if err != nil {
+		log.Error(err, "error performing canary service check", "url", url)
+	}
+}
+
+// probeServiceURL issues a GET against the given URL and verifies the
+// expected canary healthcheck response.
+func (r *reconciler) probeServiceURL(ctx context.Context, url string) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("error creating canary service HTTP request %s: %v", url, err)
+	}
+
+	// The ClusterIP is only reachable from inside the cluster, and
+	// sending the probe through a proxy would defeat its purpose of
+	// isolating the router from the canary pods, so never proxy it.
+	client := &http.Client{
+		Timeout: defaultProbeTimeout,
+		Transport: &http.Transport{
+			Proxy: nil,
+		},
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("error sending canary service HTTP request %s: %v", url, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected canary service response status code %d", response.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("error reading canary service response body: %v", err)
+	}
+	if !strings.Contains(string(body), CanaryHealthcheckResponse) {
+		return fmt.Errorf("canary service response body does not match expected value")
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package canary
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCanaryServiceURL(t *testing.T) {
+	serviceFor := func(clusterIP string, ports ...int32) *corev1.Service {
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "openshift-ingress-canary",
+				Name:      "ingress-canary",
+			},
+			Spec: corev1.ServiceSpec{
+				ClusterIP: clusterIP,
+			},
+		}
+		for _, port := range ports {
+			service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{Port: port})
+		}
+		return service
+	}
+
+	testCases := []struct {
+		description   string
+		service       *corev1.Service
+		expectedURL   string
+		expectedError string
+	}{
+		{
+			description: "IPv4 ClusterIP with one port",
+			service:     serviceFor("172.30.0.10", 8080),
+			expectedURL: "http://172.30.0.10:8080",
+		},
+		{
+			description: "multiple ports use the first port",
+			service:     serviceFor("172.30.0.10", 8080, 8888),
+			expectedURL: "http://172.30.0.10:8080",
+		},
+		{
+			description: "IPv6 ClusterIP is bracket-wrapped",
+			service:     serviceFor("fd02::10", 8080),
+			expectedURL: "http://[fd02::10]:8080",
+		},
+		{
+			description:   "headless service",
+			service:       serviceFor(corev1.ClusterIPNone, 8080),
+			expectedError: "has no ClusterIP",
+		},
+		{
+			description:   "service without ports",
+			service:       serviceFor("172.30.0.10"),
+			expectedError: "has no ports",
+		},
+	}
+
+	for _, tc := range testCases {
+		url, err := canaryServiceURL(tc.service)
+		if len(tc.expectedError) != 0 {
+			if err == nil {
+				t.Errorf("%s: expected canaryServiceURL to return an error, but it did not", tc.description)
+			} else if !strings.Contains(err.Error(), tc.expectedError) {
+				t.Errorf("%s: expected error to contain %q, but got %q", tc.description, tc.expectedError, err.Error())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: canaryServiceURL returned an unexpected error: %v", tc.description, err)
+		} else if url != tc.expectedURL {
+			t.Errorf("%s: expected URL %q, but got %q", tc.description, tc.expectedURL, url)
+		}
+	}
+}
+
+// TestProbeServiceURLIgnoresProxy verifies that the ClusterIP probe
+// connects directly even when the operator's environment configures an
+// HTTP proxy, since routing the in-cluster check through a proxy would
+// defeat its purpose of isolating the router from the canary pods.
+func TestProbeServiceURLIgnoresProxy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, CanaryHealthcheckResponse)
+	}))
+	defer server.Close()
+
+	// Point the environment proxy at an unreachable address; a probe
+	// that honors it cannot reach the server.
+	for _, name := range []string{"HTTP_PROXY", "http_proxy"} {
+		oldValue, hadValue := os.LookupEnv(name)
+		os.Setenv(name, "http://127.0.0.1:1")
+		defer func(name, oldValue string, hadValue bool) {
+			if hadValue {
+				os.Setenv(name, oldValue)
+			} else {
+				os.Unsetenv(name)
+			}
+		}(name, oldValue, hadValue)
+	}
+
+	r := &reconciler{}
+	if err := r.probeServiceURL(context.TODO(), server.URL); err != nil {
+		t.Errorf("expected the service probe to bypass the environment proxy, but got: %v", err)
+	}
+}
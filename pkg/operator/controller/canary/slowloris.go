@@ -0,0 +1,66 @@
+package canary
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+const (
+	// slowHeaderTrickleInterval is how long the slow-header probe
+	// waits between individual header bytes.
+	slowHeaderTrickleInterval = 1 * time.Second
+
+	// defaultSlowHeaderProbeWindow is how long the router is given to
+	// close a connection with slowly-trickled request headers when
+	// Config.SlowHeaderProbeWindow is unset.
+	defaultSlowHeaderProbeWindow = 30 * time.Second
+)
+
+// probeSlowHeaders verifies the router's protection against slow-loris
+// style attacks by trickling request header bytes to the route's
+// endpoint and asserting that the router closes the connection within
+// the configured window rather than holding it open indefinitely.
+func (r *reconciler) probeSlowHeaders(route *routev1.Route) error {
+	window := r.config.SlowHeaderProbeWindow
+	if window == 0 {
+		window = defaultSlowHeaderProbeWindow
+	}
+
+	conn, err := r.dialRouteProbeConn(route)
+	if err != nil {
+		return fmt.Errorf("error dialing %q for canary slow header probe: %v", route.Spec.Host, err)
+	}
+	defer conn.Close()
+
+	return checkSlowHeaderClose(conn, route.Spec.Host, slowHeaderTrickleInterval, window)
+}
+
+// checkSlowHeaderClose trickles request header bytes over the given
+// connection, one byte per trickle interval, and returns nil if the
+// peer closes the connection within the window. An error is returned if
+// the connection is still open when the window expires.
+func checkSlowHeaderClose(conn net.Conn, host string, trickle, window time.Duration) error {
+	deadline := time.Now().Add(window)
+	if err := conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("error setting deadline for canary slow header probe: %v", err)
+	}
+
+	header := fmt.Sprintf("GET / HTTP/1.1\r\nHost: %s\r\nX-Canary-Slow-Header: ", host)
+	if _, err := conn.Write([]byte(header)); err != nil {
+		// The connection was closed before the headers completed,
+		// which is the expected protective behavior.
+		return nil
+	}
+
+	for time.Now().Before(deadline) {
+		if _, err := conn.Write([]byte("a")); err != nil {
+			return nil
+		}
+		time.Sleep(trickle)
+	}
+
+	return fmt.Errorf("expected connection to be closed within %v while trickling request headers, but it stayed open", window)
+}
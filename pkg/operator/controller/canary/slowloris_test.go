@@ -0,0 +1,59 @@
+package canary
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckSlowHeaderClose(t *testing.T) {
+	t.Run("server with a header timeout closes the connection", func(t *testing.T) {
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+		server.Config.ReadHeaderTimeout = 50 * time.Millisecond
+		server.Start()
+		defer server.Close()
+
+		conn, err := net.Dial("tcp", server.Listener.Addr().String())
+		if err != nil {
+			t.Fatalf("failed to dial test server: %v", err)
+		}
+		defer conn.Close()
+
+		if err := checkSlowHeaderClose(conn, "canary.example.com", 10*time.Millisecond, 5*time.Second); err != nil {
+			t.Errorf("expected slow header probe to pass against a server with a header timeout, but got: %v", err)
+		}
+	})
+
+	t.Run("server without a header timeout holds the connection open", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("failed to create listener: %v", err)
+		}
+		defer listener.Close()
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				// Read forever without enforcing any header
+				// timeout.
+				go io.Copy(ioutil.Discard, conn)
+			}
+		}()
+
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		if err != nil {
+			t.Fatalf("failed to dial test listener: %v", err)
+		}
+		defer conn.Close()
+
+		if err := checkSlowHeaderClose(conn, "canary.example.com", 10*time.Millisecond, 200*time.Millisecond); err == nil {
+			t.Error("expected slow header probe to fail against a server without a header timeout, but it passed")
+		}
+	})
+}
@@ -0,0 +1,33 @@
+package canary
+
+// defaultSuccessRatioWindow is how many recent probe results the
+// success ratio is computed over when Config.SuccessRatioWindow is
+// unset.
+const defaultSuccessRatioWindow = 10
+
+// recordSuccessRatio appends the given probe outcome to the sliding
+// window of recent results and returns the fraction of successes
+// within the window, so that operators can alert on a dropping
+// success ratio rather than a single binary reachability flip.
+func (r *reconciler) recordSuccessRatio(success bool) float64 {
+	window := r.config.SuccessRatioWindow
+	if window == 0 {
+		window = defaultSuccessRatioWindow
+	}
+
+	r.successRatioMu.Lock()
+	defer r.successRatioMu.Unlock()
+
+	r.successWindow = append(r.successWindow, success)
+	if len(r.successWindow) > window {
+		r.successWindow = r.successWindow[len(r.successWindow)-window:]
+	}
+
+	successes := 0
+	for _, succeeded := range r.successWindow {
+		if succeeded {
+			successes++
+		}
+	}
+	return float64(successes) / float64(len(r.successWindow))
+}
@@ -0,0 +1,54 @@
+package canary
+
+import (
+	"testing"
+)
+
+func TestRecordSuccessRatio(t *testing.T) {
+	testCases := []struct {
+		description string
+		window      int
+		outcomes    []bool
+		expected    float64
+	}{
+		{
+			description: "all successes",
+			window:      4,
+			outcomes:    []bool{true, true, true},
+			expected:    1.0,
+		},
+		{
+			description: "mixed outcomes within the window",
+			window:      4,
+			outcomes:    []bool{true, false, true, false},
+			expected:    0.5,
+		},
+		{
+			description: "old outcomes fall out of the window",
+			window:      3,
+			outcomes:    []bool{false, false, true, true, true},
+			expected:    1.0,
+		},
+		{
+			description: "zero window falls back to the default",
+			window:      0,
+			outcomes:    []bool{false, true},
+			expected:    0.5,
+		},
+	}
+
+	for _, tc := range testCases {
+		r := &reconciler{
+			config: Config{
+				SuccessRatioWindow: tc.window,
+			},
+		}
+		ratio := 0.0
+		for _, outcome := range tc.outcomes {
+			ratio = r.recordSuccessRatio(outcome)
+		}
+		if ratio != tc.expected {
+			t.Errorf("%s: expected success ratio %v, but got %v", tc.description, tc.expected, ratio)
+		}
+	}
+}
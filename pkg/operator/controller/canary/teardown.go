@@ -0,0 +1,47 @@
+package canary
+
+import (
+	"context"
+	"fmt"
+)
+
+// ensureCanaryResourcesDeleted tears down the canary route, service,
+// daemonset, and namespace. It is called when the default ingress
+// controller no longer exists so that canary objects are not left
+// orphaned. Each step tolerates an already-deleted object, so teardown
+// is idempotent across repeated reconciles.
+func (r *reconciler) ensureCanaryResourcesDeleted(ctx context.Context) error {
+	if haveRoute, route, err := r.currentCanaryRoute(ctx); err != nil {
+		return fmt.Errorf("failed to get canary route for deletion: %v", err)
+	} else if haveRoute {
+		if _, err := r.deleteCanaryRoute(ctx, route); err != nil {
+			return err
+		}
+	}
+
+	if haveService, service, err := r.currentCanaryService(ctx); err != nil {
+		return fmt.Errorf("failed to get canary service for deletion: %v", err)
+	} else if haveService {
+		if err := r.deleteCanaryService(ctx, service); err != nil {
+			return err
+		}
+	}
+
+	if haveDs, daemonset, err := r.currentCanaryDaemonSet(ctx); err != nil {
+		return fmt.Errorf("failed to get canary daemonset for deletion: %v", err)
+	} else if haveDs {
+		if err := r.deleteCanaryDaemonSet(ctx, daemonset); err != nil {
+			return err
+		}
+	}
+
+	if haveNamespace, ns, err := r.currentCanaryNamespace(); err != nil {
+		return fmt.Errorf("failed to get canary namespace for deletion: %v", err)
+	} else if haveNamespace {
+		if err := r.deleteCanaryNamespace(ctx, ns); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package canary
+
+import (
+	"context"
+	"testing"
+
+	routev1 "github.com/openshift/api/route/v1"
+
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+	"github.com/openshift/cluster-ingress-operator/pkg/operator/controller"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestEnsureCanaryResourcesDeleted(t *testing.T) {
+	daemonsetRef := metav1.OwnerReference{
+		Name: "test",
+	}
+	service := desiredCanaryService(daemonsetRef)
+	route, err := desiredCanaryRoute(Config{}, service)
+	if err != nil {
+		t.Fatalf("desiredCanaryRoute returned an error: %v", err)
+	}
+	daemonset := desiredCanaryDaemonSet("openshift/origin-cluster-ingress-operator:latest", corev1.ResourceRequirements{})
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: controller.DefaultCanaryNamespace,
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(operatorclient.GetScheme()).WithObjects(ns, daemonset, service, route).Build()
+	r := &reconciler{
+		client: fakeClient,
+	}
+
+	if err := r.ensureCanaryResourcesDeleted(context.TODO()); err != nil {
+		t.Fatalf("ensureCanaryResourcesDeleted returned an error: %v", err)
+	}
+
+	if err := fakeClient.Get(context.TODO(), controller.CanaryRouteName(), &routev1.Route{}); !errors.IsNotFound(err) {
+		t.Errorf("expected canary route to be deleted, but got: %v", err)
+	}
+	if err := fakeClient.Get(context.TODO(), controller.CanaryServiceName(), &corev1.Service{}); !errors.IsNotFound(err) {
+		t.Errorf("expected canary service to be deleted, but got: %v", err)
+	}
+	if err := fakeClient.Get(context.TODO(), controller.CanaryDaemonSetName(), &appsv1.DaemonSet{}); !errors.IsNotFound(err) {
+		t.Errorf("expected canary daemonset to be deleted, but got: %v", err)
+	}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Name: controller.DefaultCanaryNamespace}, &corev1.Namespace{}); !errors.IsNotFound(err) {
+		t.Errorf("expected canary namespace to be deleted, but got: %v", err)
+	}
+
+	// Teardown is idempotent: a second pass with nothing left is a
+	// no-op.
+	if err := r.ensureCanaryResourcesDeleted(context.TODO()); err != nil {
+		t.Fatalf("expected repeated ensureCanaryResourcesDeleted to be a no-op, but got: %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package canary
+
+import (
+	"errors"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// throttlingRequeueDelay is how long a throttled reconcile waits before
+// being retried.
+const throttlingRequeueDelay = 30 * time.Second
+
+// isThrottlingError returns true if the given error, or any error it
+// wraps, indicates that the API server throttled the request.
+func isThrottlingError(err error) bool {
+	for err != nil {
+		if apierrors.IsTooManyRequests(err) {
+			return true
+		}
+		err = errors.Unwrap(err)
+	}
+	return false
+}
+
+// transientRequeueBaseDelay is the delay before the first retry of a
+// reconcile that failed with a transient API error. The delay doubles
+// on each successive transient failure up to transientRequeueMaxDelay.
+const transientRequeueBaseDelay = 1 * time.Second
+
+// transientRequeueMaxDelay caps the backoff between retries of
+// reconciles that keep failing with transient API errors.
+const transientRequeueMaxDelay = 1 * time.Minute
+
+// isTransientAPIError returns true if the given error, or an error it
+// wraps, is a transient API error that a retry can be expected to
+// resolve, as opposed to a terminal error such as a validation
+// failure.
+func isTransientAPIError(err error) bool {
+	return apierrors.IsConflict(err) || apierrors.IsServerTimeout(err)
+}
+
+// nextTransientRequeueDelay returns the backoff before the next retry
+// after a transient reconcile failure, doubling the delay on each
+// successive failure up to the cap.
+func (r *reconciler) nextTransientRequeueDelay() time.Duration {
+	delay := transientRequeueBaseDelay << r.transientFailures
+	if delay >= transientRequeueMaxDelay {
+		return transientRequeueMaxDelay
+	}
+	r.transientFailures++
+	return delay
+}
+
+// resetTransientBackoff clears the transient failure count once a
+// reconcile completes without a transient error.
+func (r *reconciler) resetTransientBackoff() {
+	r.transientFailures = 0
+}
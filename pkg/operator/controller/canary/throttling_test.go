@@ -0,0 +1,134 @@
+package canary
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	operatorclient "github.com/openshift/cluster-ingress-operator/pkg/operator/client"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// throttlingClient wraps a client and fails every Get with a
+// too-many-requests error to simulate API server throttling.
+type throttlingClient struct {
+	client.Client
+}
+
+func (c *throttlingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	return apierrors.NewTooManyRequests("the server is throttling requests", 1)
+}
+
+func TestIsThrottlingError(t *testing.T) {
+	throttled := apierrors.NewTooManyRequests("the server is throttling requests", 1)
+	testCases := []struct {
+		description string
+		err         error
+		expect      bool
+	}{
+		{
+			description: "bare throttling error",
+			err:         throttled,
+			expect:      true,
+		},
+		{
+			description: "wrapped throttling error",
+			err:         fmt.Errorf("failed to ensure canary daemonset: %w", throttled),
+			expect:      true,
+		},
+		{
+			description: "unrelated error",
+			err:         fmt.Errorf("failed to ensure canary daemonset"),
+			expect:      false,
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := isThrottlingError(tc.err); got != tc.expect {
+			t.Errorf("%s: expected isThrottlingError to be %t, but got %t", tc.description, tc.expect, got)
+		}
+	}
+}
+
+// conflictingClient wraps a client and fails every Get with a
+// conflict error to simulate a transient API failure.
+type conflictingClient struct {
+	client.Client
+}
+
+func (c *conflictingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	return apierrors.NewConflict(schema.GroupResource{Resource: "ingresscontrollers"}, "default", fmt.Errorf("the object has been modified"))
+}
+
+func TestReconcileTransientError(t *testing.T) {
+	r := &reconciler{
+		client: &conflictingClient{fake.NewClientBuilder().WithScheme(operatorclient.GetScheme()).Build()},
+		config: Config{
+			CanaryImage: "openshift/origin-cluster-ingress-operator:latest",
+		},
+	}
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: "openshift-ingress-operator",
+			Name:      "default",
+		},
+	}
+
+	// Successive transient failures back off with doubling delays up
+	// to the cap.
+	expectedDelays := []time.Duration{
+		transientRequeueBaseDelay,
+		2 * transientRequeueBaseDelay,
+		4 * transientRequeueBaseDelay,
+	}
+	for i, expectedDelay := range expectedDelays {
+		result, err := r.Reconcile(context.TODO(), request)
+		if err != nil {
+			t.Fatalf("expected a conflicted reconcile to requeue rather than return an error, but got: %v", err)
+		}
+		if result.RequeueAfter != expectedDelay {
+			t.Errorf("expected conflicted reconcile %d to requeue after %v, but got %v", i+1, expectedDelay, result.RequeueAfter)
+		}
+	}
+
+	// A terminal error resets the backoff and is returned as-is.
+	r.client = fake.NewClientBuilder().WithScheme(operatorclient.GetScheme()).Build()
+	if _, err := r.Reconcile(context.TODO(), request); err != nil {
+		t.Fatalf("Reconcile returned an unexpected error: %v", err)
+	}
+	if r.transientFailures != 0 {
+		t.Errorf("expected the transient failure count to reset after a successful reconcile, but got %d", r.transientFailures)
+	}
+}
+
+func TestReconcileThrottled(t *testing.T) {
+	r := &reconciler{
+		client: &throttlingClient{fake.NewClientBuilder().WithScheme(operatorclient.GetScheme()).Build()},
+		config: Config{
+			CanaryImage: "openshift/origin-cluster-ingress-operator:latest",
+		},
+	}
+
+	request := reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: "openshift-ingress-operator",
+			Name:      "default",
+		},
+	}
+	result, err := r.Reconcile(context.TODO(), request)
+	if err != nil {
+		t.Fatalf("expected a throttled reconcile to requeue rather than return an error, but got: %v", err)
+	}
+	if result.RequeueAfter != throttlingRequeueDelay {
+		t.Errorf("expected a throttled reconcile to requeue after %v, but got %v", throttlingRequeueDelay, result.RequeueAfter)
+	}
+}
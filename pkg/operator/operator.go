@@ -140,7 +140,7 @@ func New(config operatorconfig.Config, kubeConfig *rest.Config) (*Operator, erro
 		if _, err := canarycontroller.New(mgr, canarycontroller.Config{
 			Namespace:   config.Namespace,
 			CanaryImage: config.CanaryImage,
-			Stop:        config.Stop,
+			UserAgent:   "openshift-ingress-canary/" + config.OperatorReleaseVersion,
 		}); err != nil {
 			return nil, fmt.Errorf("failed to create canary controller: %v", err)
 		}
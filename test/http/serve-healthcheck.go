@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -56,6 +57,16 @@ func NewServeHealthCheckCommand() *cobra.Command {
 
 func serveHealthCheck() {
 	http.HandleFunc("/", healthCheckHandler)
+
+	// When the operator overrides the canary port count, it passes the
+	// full port list via the environment; listen on every port.
+	if ports := os.Getenv(canarycontroller.CanaryHealthcheckPortsEnvName); len(ports) != 0 {
+		for _, port := range strings.Split(ports, ",") {
+			go listenAndServe(strings.TrimSpace(port))
+		}
+		select {}
+	}
+
 	port := os.Getenv("PORT")
 	if len(port) == 0 {
 		port = "8080"